@@ -0,0 +1,216 @@
+package cluster
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"port-scanner/internal/scanner"
+	"port-scanner/internal/types"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultRetries    = 5
+	DefaultRetryDelay = 2 * time.Second
+	requestTimeout    = time.Minute
+)
+
+var (
+	invalidCoordinatorError = errors.New("invalid coordinator url: expected http(s)://host:port")
+	coordinatorError        = errors.New("coordinator request failed")
+)
+
+type AgentOptions struct {
+	Coordinator string
+	Token       string
+	Name        string
+	Retries     int
+	RetryDelay  time.Duration
+	Client      *http.Client
+	Reporters   []scanner.Reporter
+}
+
+type AgentStats struct {
+	Batches  int
+	Scanned  int
+	Open     int
+	Rejected int
+}
+
+type Agent struct {
+	opts AgentOptions
+	base string
+}
+
+func NewAgent(opts AgentOptions) (*Agent, error) {
+	u, err := url.Parse(opts.Coordinator)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("%w: %q", invalidCoordinatorError, opts.Coordinator)
+	}
+
+	if opts.Name == "" {
+		opts.Name = agentName()
+	}
+	if opts.Retries < 0 {
+		opts.Retries = 0
+	}
+	if opts.RetryDelay <= 0 {
+		opts.RetryDelay = DefaultRetryDelay
+	}
+	if opts.Client == nil {
+		opts.Client = &http.Client{Timeout: requestTimeout}
+	}
+
+	return &Agent{opts: opts, base: strings.TrimSuffix(opts.Coordinator, "/")}, nil
+}
+
+func (a *Agent) Name() string {
+	return a.opts.Name
+}
+
+func (a *Agent) Run(ctx context.Context) (AgentStats, error) {
+	var stats AgentStats
+	failures := 0
+
+	for ctx.Err() == nil {
+		batch, status, err := a.lease(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+			if status == http.StatusUnauthorized || status == http.StatusBadRequest {
+				return stats, err
+			}
+			failures++
+			if failures > a.opts.Retries {
+				return stats, err
+			}
+			wait(ctx, a.opts.RetryDelay)
+			continue
+		}
+		failures = 0
+
+		switch status {
+		case http.StatusGone:
+			return stats, nil
+		case http.StatusNoContent:
+			continue
+		}
+
+		results, err := scanner.ScanTasks(ctx, batch.Settings.config(), batch.Tasks, a.opts.Reporters...)
+		if err != nil {
+			break
+		}
+
+		done, err := a.submit(ctx, batch.ID, results)
+		if err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+			stats.Rejected++
+			continue
+		}
+
+		stats.Batches++
+		stats.Scanned += len(results)
+		for _, r := range results {
+			if r.Status {
+				stats.Open++
+			}
+		}
+		if done {
+			return stats, nil
+		}
+	}
+	return stats, nil
+}
+
+func (a *Agent) lease(ctx context.Context) (Batch, int, error) {
+	var batch Batch
+	status, err := a.post(ctx, leasePath, leaseRequest{Agent: a.opts.Name}, &batch)
+	if err != nil {
+		return Batch{}, status, err
+	}
+	return batch, status, nil
+}
+
+func (a *Agent) submit(ctx context.Context, id string, results []types.Result) (bool, error) {
+	var resp submitResponse
+	for attempt := 0; ; attempt++ {
+		status, err := a.post(ctx, batchesPath+url.PathEscape(id), submission{Agent: a.opts.Name, Results: results}, &resp)
+		if err == nil {
+			return resp.Done, nil
+		}
+		if status != 0 || attempt >= a.opts.Retries || ctx.Err() != nil {
+			return false, err
+		}
+		wait(ctx, a.opts.RetryDelay)
+	}
+}
+
+func (a *Agent) post(ctx context.Context, path string, body any, out any) (int, error) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.base+path, bytes.NewReader(payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", contentTypeJSON)
+	if a.opts.Token != "" {
+		req.Header.Set("Authorization", bearerPrefix+a.opts.Token)
+	}
+
+	resp, err := a.opts.Client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", coordinatorError, err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		err = json.NewDecoder(resp.Body).Decode(out)
+		if err != nil {
+			return 0, fmt.Errorf("%w: invalid response: %v", coordinatorError, err)
+		}
+		return resp.StatusCode, nil
+	case http.StatusNoContent, http.StatusGone:
+		return resp.StatusCode, nil
+	}
+
+	var e errorResponse
+	_ = json.NewDecoder(resp.Body).Decode(&e)
+	if e.Error == "" {
+		e.Error = http.StatusText(resp.StatusCode)
+	}
+	return resp.StatusCode, fmt.Errorf("%w: %d %s", coordinatorError, resp.StatusCode, e.Error)
+}
+
+func agentName() string {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "agent"
+	}
+	return host + "-" + strconv.Itoa(os.Getpid())
+}
+
+func wait(ctx context.Context, d time.Duration) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+}
@@ -0,0 +1,292 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"port-scanner/internal/scanner"
+	"port-scanner/internal/types"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type countingReporter struct {
+	mu    sync.Mutex
+	total int
+	count int
+}
+
+func (r *countingReporter) Start(total int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.total = total
+}
+
+func (r *countingReporter) Increment(types.Result) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.count++
+}
+
+func (r *countingReporter) Finish() {}
+
+func openPorts(t *testing.T, n int) []int {
+	t.Helper()
+	ports := make([]int, 0, n)
+	for i := 0; i < n; i++ {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("listen: %v", err)
+		}
+		t.Cleanup(func() {
+			_ = listener.Close()
+		})
+		ports = append(ports, listener.Addr().(*net.TCPAddr).Port)
+	}
+	return ports
+}
+
+func closedPorts(t *testing.T, n int) []int {
+	t.Helper()
+	ports := openPorts(t, 0)
+	for i := 0; i < n; i++ {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("listen: %v", err)
+		}
+		ports = append(ports, listener.Addr().(*net.TCPAddr).Port)
+		_ = listener.Close()
+	}
+	return ports
+}
+
+func joinPorts(ports ...[]int) string {
+	parts := make([]string, 0)
+	for _, list := range ports {
+		for _, port := range list {
+			parts = append(parts, strconv.Itoa(port))
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+func listen(t *testing.T) (net.Listener, string) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	return listener, "http://" + listener.Addr().String()
+}
+
+func TestCoordinateWithAgents(t *testing.T) {
+	open := openPorts(t, 3)
+	closed := closedPorts(t, 7)
+	cfg := types.Config{Address: "127.0.0.1", Ports: joinPorts(open, closed), Mode: "rapid", Timeout: 500}
+
+	listener, url := listen(t)
+	reporter := &countingReporter{}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	type outcome struct {
+		report types.Report
+		err    error
+	}
+	finished := make(chan outcome, 1)
+	go func() {
+		report, err := Coordinate(ctx, listener, cfg, Options{Token: "secret", BatchSize: 2, PollWait: 50 * time.Millisecond}, reporter)
+		finished <- outcome{report, err}
+	}()
+
+	const agents = 3
+	stats := make([]AgentStats, agents)
+	errs := make([]error, agents)
+	var wg sync.WaitGroup
+	for i := 0; i < agents; i++ {
+		agent, err := NewAgent(AgentOptions{Coordinator: url, Token: "secret", Name: fmt.Sprintf("agent-%d", i), RetryDelay: 10 * time.Millisecond})
+		if err != nil {
+			t.Fatalf("NewAgent() unexpected error: %v", err)
+		}
+
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			stats[i], errs[i] = agent.Run(ctx)
+		}(i)
+	}
+
+	result := <-finished
+	wg.Wait()
+	if result.err != nil {
+		t.Fatalf("Coordinate() unexpected error: %v", result.err)
+	}
+
+	scanned, batches := 0, 0
+	for i := range stats {
+		if errs[i] != nil {
+			t.Errorf("agent %d: unexpected error: %v", i, errs[i])
+		}
+		scanned += stats[i].Scanned
+		batches += stats[i].Batches
+	}
+	if scanned != 10 || batches != 5 {
+		t.Errorf("agents scanned %d probes in %d batches, want 10 in 5", scanned, batches)
+	}
+
+	report := result.report
+	if report.Summary.Scanned != 10 || report.Summary.Open != 3 || len(report.Results) != 10 {
+		t.Fatalf("summary = %+v with %d results, want 10 probes and 3 open", report.Summary, len(report.Results))
+	}
+	for i, port := range append(open, closed...) {
+		r := report.Results[i]
+		if r.Host != "127.0.0.1" || r.Port != port || r.Status != (i < len(open)) {
+			t.Errorf("result %d = %+v, want port %d open=%v", i, r, port, i < len(open))
+		}
+	}
+	if reporter.total != 10 || reporter.count != 10 {
+		t.Errorf("reporter saw %d of %d, want 10 of 10", reporter.count, reporter.total)
+	}
+	if report.Metadata.Mode != "rapid" || report.Metadata.End.Before(report.Metadata.Start) {
+		t.Errorf("metadata = %+v, want mode and times", report.Metadata)
+	}
+}
+
+func TestLeaseExpiry(t *testing.T) {
+	closed := closedPorts(t, 3)
+	cfg := types.Config{Address: "127.0.0.1", Ports: joinPorts(closed), Timeout: 500}
+
+	c, _, _, err := NewCoordinator(context.Background(), cfg, Options{BatchSize: 3, LeaseTimeout: 50 * time.Millisecond, PollWait: time.Second}, &countingReporter{})
+	if err != nil {
+		t.Fatalf("NewCoordinator() unexpected error: %v", err)
+	}
+	srv := httptest.NewServer(c)
+	defer srv.Close()
+
+	stale, err := NewAgent(AgentOptions{Coordinator: srv.URL, Name: "stale"})
+	if err != nil {
+		t.Fatalf("NewAgent() unexpected error: %v", err)
+	}
+	batch, status, err := stale.lease(context.Background())
+	if err != nil || status != http.StatusOK || len(batch.Tasks) != 3 {
+		t.Fatalf("lease() = %+v, %d, %v, want a batch of 3 tasks", batch, status, err)
+	}
+
+	agent, err := NewAgent(AgentOptions{Coordinator: srv.URL, Name: "fresh"})
+	if err != nil {
+		t.Fatalf("NewAgent() unexpected error: %v", err)
+	}
+	stats, err := agent.Run(context.Background())
+	if err != nil || stats.Batches != 1 || stats.Scanned != 3 {
+		t.Fatalf("Run() = %+v, %v, want the expired batch scanned", stats, err)
+	}
+
+	results := make([]types.Result, len(batch.Tasks))
+	for i, task := range batch.Tasks {
+		results[i] = types.Result{Host: task.Host, Port: task.Port}
+	}
+	_, err = stale.submit(context.Background(), batch.ID, results)
+	if !errors.Is(err, coordinatorError) || !strings.Contains(err.Error(), "409") {
+		t.Errorf("submit() of completed batch error = %v, want 409", err)
+	}
+
+	got := c.Status()
+	if !got.Done || got.Completed != 3 || strings.Join(got.Agents, ",") != "fresh,stale" {
+		t.Errorf("Status() = %+v, want done by both agents", got)
+	}
+}
+
+func TestSubmitMismatch(t *testing.T) {
+	c, _, _, err := NewCoordinator(context.Background(), types.Config{Address: "127.0.0.1", Ports: "1,2"}, Options{}, &countingReporter{})
+	if err != nil {
+		t.Fatalf("NewCoordinator() unexpected error: %v", err)
+	}
+
+	batch, ok, _ := c.next("agent", time.Now())
+	if !ok {
+		t.Fatal("next() returned no batch")
+	}
+
+	tests := []struct {
+		name    string
+		id      string
+		results []types.Result
+		wantErr error
+	}{
+		{"unknown batch", "99", nil, batchNotFoundError},
+		{"missing results", batch.ID, []types.Result{{Host: "127.0.0.1", Port: 1}}, resultMismatchError},
+		{"wrong port", batch.ID, []types.Result{{Host: "127.0.0.1", Port: 1}, {Host: "127.0.0.1", Port: 3}}, resultMismatchError},
+		{"complete", batch.ID, []types.Result{{Host: "127.0.0.1", Port: 1}, {Host: "127.0.0.1", Port: 2, Status: true}}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := c.complete(tt.id, submission{Results: tt.results})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("complete() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if !c.results[1].Status {
+		t.Errorf("results = %+v, want the submitted results stored", c.results)
+	}
+}
+
+func TestUnauthorizedAgent(t *testing.T) {
+	c, _, _, err := NewCoordinator(context.Background(), types.Config{Address: "127.0.0.1", Ports: "1"}, Options{Token: "secret"}, &countingReporter{})
+	if err != nil {
+		t.Fatalf("NewCoordinator() unexpected error: %v", err)
+	}
+	srv := httptest.NewServer(c)
+	defer srv.Close()
+
+	agent, err := NewAgent(AgentOptions{Coordinator: srv.URL, Token: "wrong", RetryDelay: time.Millisecond})
+	if err != nil {
+		t.Fatalf("NewAgent() unexpected error: %v", err)
+	}
+
+	_, err = agent.Run(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("Run() error = %v, want 401", err)
+	}
+}
+
+func TestNewAgent(t *testing.T) {
+	tests := []struct {
+		url     string
+		wantErr bool
+	}{
+		{"http://127.0.0.1:8080", false},
+		{"https://coordinator.example.com/", false},
+		{"127.0.0.1:8080", true},
+		{"ftp://example.com", true},
+		{"", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			_, err := NewAgent(AgentOptions{Coordinator: tt.url})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewAgent() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCoordinateCanceled(t *testing.T) {
+	listener, _ := listen(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := Coordinate(ctx, listener, types.Config{Address: "127.0.0.1", Ports: "1"}, Options{}, scanner.NewReporter(scanner.ProgressNone))
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Coordinate() error = %v, want %v", err, context.Canceled)
+	}
+}
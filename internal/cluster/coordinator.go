@@ -0,0 +1,378 @@
+package cluster
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"port-scanner/internal/scanner"
+	"port-scanner/internal/types"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultBatchSize    = 256
+	DefaultLeaseTimeout = 5 * time.Minute
+	DefaultPollWait     = 10 * time.Second
+	maxBodySize         = 64 << 20
+	shutdownTimeout     = 5 * time.Second
+	drainTimeout        = time.Second
+)
+
+var (
+	unauthorizedError   = errors.New("unauthorized")
+	missingAgentError   = errors.New(`lease requires an "agent" name`)
+	batchNotFoundError  = errors.New("batch not found or already completed")
+	resultMismatchError = errors.New("results do not match the batch tasks")
+	stoppedError        = errors.New("coordinator stopped")
+)
+
+type Options struct {
+	Token        string
+	BatchSize    int
+	LeaseTimeout time.Duration
+	PollWait     time.Duration
+}
+
+type Coordinator struct {
+	opts     Options
+	settings Settings
+	reporter scanner.Reporter
+	mux      *http.ServeMux
+
+	mu        sync.Mutex
+	tasks     chan types.Task
+	total     int
+	completed int
+	results   []types.Result
+	batches   map[string]*lease
+	queue     []string
+	sequence  int
+	agents    map[string]bool
+	done      chan struct{}
+	stopped   chan struct{}
+	stopOnce  sync.Once
+}
+
+type lease struct {
+	batch    Batch
+	agent    string
+	deadline time.Time
+}
+
+func NewCoordinator(ctx context.Context, cfg types.Config, opts Options, reporter scanner.Reporter) (*Coordinator, types.Summary, types.Metadata, error) {
+	tasks, summary, metadata, err := scanner.Plan(ctx, cfg)
+	if err != nil {
+		return nil, types.Summary{}, types.Metadata{}, err
+	}
+
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultBatchSize
+	}
+	if opts.LeaseTimeout <= 0 {
+		opts.LeaseTimeout = DefaultLeaseTimeout
+	}
+	if opts.PollWait <= 0 {
+		opts.PollWait = DefaultPollWait
+	}
+
+	c := &Coordinator{
+		opts:     opts,
+		settings: settingsFrom(cfg),
+		reporter: reporter,
+		mux:      http.NewServeMux(),
+		tasks:    tasks,
+		total:    summary.Scanned,
+		batches:  make(map[string]*lease),
+		agents:   make(map[string]bool),
+		done:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+	if c.total == 0 {
+		close(c.done)
+	}
+
+	c.mux.HandleFunc("POST "+leasePath, c.lease)
+	c.mux.HandleFunc("POST "+batchesPath+"{id}", c.submit)
+	c.mux.HandleFunc("GET "+statusPath, c.status)
+	return c, summary, metadata, nil
+}
+
+func Coordinate(ctx context.Context, listener net.Listener, cfg types.Config, opts Options, reporter scanner.Reporter) (types.Report, error) {
+	c, summary, metadata, err := NewCoordinator(ctx, cfg, opts, reporter)
+	if err != nil {
+		return types.Report{}, err
+	}
+
+	start := time.Now()
+	results, err := c.Serve(ctx, listener)
+	if err != nil {
+		return types.Report{}, err
+	}
+
+	summary = scanner.Tally(summary, results)
+
+	metadata.Start = start
+	metadata.End = time.Now()
+
+	return types.Report{
+		Results:  results,
+		Summary:  summary,
+		Metadata: metadata,
+	}, nil
+}
+
+func (c *Coordinator) Serve(ctx context.Context, listener net.Listener) ([]types.Result, error) {
+	c.reporter.Start(c.total)
+	defer c.reporter.Finish()
+
+	server := &http.Server{Handler: c, ReadHeaderTimeout: shutdownTimeout}
+	served := make(chan error, 1)
+	go func() {
+		served <- server.Serve(listener)
+	}()
+
+	var err error
+	select {
+	case <-c.done:
+		wait(ctx, drainTimeout)
+	case <-ctx.Done():
+		err = ctx.Err()
+		c.stop()
+	case err = <-served:
+		c.stop()
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	_ = server.Shutdown(shutdownCtx)
+
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.results, nil
+}
+
+func (c *Coordinator) Status() Status {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	agents := make([]string, 0, len(c.agents))
+	for agent := range c.agents {
+		agents = append(agents, agent)
+	}
+	slices.Sort(agents)
+
+	leased := 0
+	for _, l := range c.batches {
+		if l.agent != "" {
+			leased++
+		}
+	}
+
+	return Status{
+		Total:     c.total,
+		Completed: c.completed,
+		Leased:    leased,
+		Agents:    agents,
+		Done:      c.completed == c.total,
+	}
+}
+
+func (c *Coordinator) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !c.authorized(r) {
+		writeError(w, http.StatusUnauthorized, unauthorizedError)
+		return
+	}
+	c.mux.ServeHTTP(w, r)
+}
+
+func (c *Coordinator) authorized(r *http.Request) bool {
+	if c.opts.Token == "" {
+		return true
+	}
+
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), bearerPrefix)
+	return ok && subtle.ConstantTimeCompare([]byte(token), []byte(c.opts.Token)) == 1
+}
+
+func (c *Coordinator) lease(w http.ResponseWriter, r *http.Request) {
+	var req leaseRequest
+	err := json.NewDecoder(http.MaxBytesReader(nil, r.Body, maxBodySize)).Decode(&req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+	if req.Agent == "" {
+		writeError(w, http.StatusBadRequest, missingAgentError)
+		return
+	}
+
+	poll := time.NewTimer(c.opts.PollWait)
+	defer poll.Stop()
+
+	for {
+		batch, ok, next := c.next(req.Agent, time.Now())
+		if ok {
+			writeJSON(w, http.StatusOK, batch)
+			return
+		}
+
+		expiry := time.NewTimer(time.Until(next))
+		select {
+		case <-c.done:
+			expiry.Stop()
+			w.WriteHeader(http.StatusGone)
+			return
+		case <-c.stopped:
+			expiry.Stop()
+			writeError(w, http.StatusServiceUnavailable, stoppedError)
+			return
+		case <-r.Context().Done():
+			expiry.Stop()
+			return
+		case <-poll.C:
+			expiry.Stop()
+			w.WriteHeader(http.StatusNoContent)
+			return
+		case <-expiry.C:
+		}
+		expiry.Stop()
+	}
+}
+
+func (c *Coordinator) next(agent string, now time.Time) (Batch, bool, time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.agents[agent] = true
+	next := now.Add(c.opts.LeaseTimeout)
+	for _, l := range c.batches {
+		if l.agent != "" && now.After(l.deadline) {
+			l.agent = ""
+			c.queue = append(c.queue, l.batch.ID)
+		}
+	}
+
+	for len(c.queue) > 0 {
+		id := c.queue[0]
+		c.queue = c.queue[1:]
+		if l, ok := c.batches[id]; ok && l.agent == "" {
+			return c.assign(l, agent, now), true, next
+		}
+	}
+
+	if batch, ok := c.batch(); ok {
+		l := &lease{batch: batch}
+		c.batches[batch.ID] = l
+		return c.assign(l, agent, now), true, next
+	}
+
+	for _, l := range c.batches {
+		if l.deadline.Before(next) {
+			next = l.deadline
+		}
+	}
+	return Batch{}, false, next
+}
+
+func (c *Coordinator) assign(l *lease, agent string, now time.Time) Batch {
+	l.agent = agent
+	l.deadline = now.Add(c.opts.LeaseTimeout)
+	return l.batch
+}
+
+func (c *Coordinator) batch() (Batch, bool) {
+	tasks := make([]types.Task, 0, min(c.opts.BatchSize, c.total))
+	for len(tasks) < c.opts.BatchSize {
+		task, ok := <-c.tasks
+		if !ok {
+			break
+		}
+		tasks = append(tasks, task)
+	}
+	if len(tasks) == 0 {
+		return Batch{}, false
+	}
+
+	c.sequence++
+	return Batch{ID: strconv.Itoa(c.sequence), Settings: c.settings, Tasks: tasks}, true
+}
+
+func (c *Coordinator) submit(w http.ResponseWriter, r *http.Request) {
+	var sub submission
+	err := json.NewDecoder(http.MaxBytesReader(nil, r.Body, maxBodySize)).Decode(&sub)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+
+	done, err := c.complete(r.PathValue("id"), sub)
+	if errors.Is(err, batchNotFoundError) {
+		writeError(w, http.StatusConflict, err)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, submitResponse{Done: done})
+}
+
+func (c *Coordinator) complete(id string, sub submission) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	l, ok := c.batches[id]
+	if !ok {
+		return c.completed == c.total, fmt.Errorf("%w: %s", batchNotFoundError, id)
+	}
+
+	if len(sub.Results) != len(l.batch.Tasks) {
+		return false, fmt.Errorf("%w: got %d results for %d tasks", resultMismatchError, len(sub.Results), len(l.batch.Tasks))
+	}
+	for i, task := range l.batch.Tasks {
+		if sub.Results[i].Host != task.Host || sub.Results[i].Port != task.Port {
+			return false, fmt.Errorf("%w: %s:%d", resultMismatchError, sub.Results[i].Host, sub.Results[i].Port)
+		}
+	}
+
+	for i, task := range l.batch.Tasks {
+		for len(c.results) <= task.Index {
+			c.results = append(c.results, types.Result{})
+		}
+		c.results[task.Index] = sub.Results[i]
+		c.reporter.Increment(sub.Results[i])
+	}
+	delete(c.batches, id)
+	if sub.Agent != "" {
+		c.agents[sub.Agent] = true
+	}
+
+	c.completed += len(l.batch.Tasks)
+	if c.completed == c.total {
+		close(c.done)
+		return true, nil
+	}
+	return false, nil
+}
+
+func (c *Coordinator) status(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, c.Status())
+}
+
+func (c *Coordinator) stop() {
+	c.stopOnce.Do(func() {
+		close(c.stopped)
+	})
+}
@@ -0,0 +1,85 @@
+package cluster
+
+import (
+	"encoding/json"
+	"net/http"
+	"port-scanner/internal/types"
+)
+
+const (
+	contentTypeJSON = "application/json"
+	bearerPrefix    = "Bearer "
+	leasePath       = "/lease"
+	batchesPath     = "/batches/"
+	statusPath      = "/status"
+)
+
+type Settings struct {
+	Mode      string `json:"mode"`
+	Timeout   int    `json:"timeout,omitempty"`
+	ScanDelay int    `json:"scan-delay,omitempty"`
+	MaxJitter int    `json:"max-jitter,omitempty"`
+	Banners   bool   `json:"banners,omitempty"`
+}
+
+type leaseRequest struct {
+	Agent string `json:"agent"`
+}
+
+type Batch struct {
+	ID       string       `json:"id"`
+	Settings Settings     `json:"settings"`
+	Tasks    []types.Task `json:"tasks"`
+}
+
+type submission struct {
+	Agent   string         `json:"agent"`
+	Results []types.Result `json:"results"`
+}
+
+type submitResponse struct {
+	Done bool `json:"done"`
+}
+
+type Status struct {
+	Total     int      `json:"total"`
+	Completed int      `json:"completed"`
+	Leased    int      `json:"leased"`
+	Agents    []string `json:"agents"`
+	Done      bool     `json:"done"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func settingsFrom(cfg types.Config) Settings {
+	return Settings{
+		Mode:      cfg.Mode,
+		Timeout:   cfg.Timeout,
+		ScanDelay: cfg.ScanDelay,
+		MaxJitter: cfg.MaxJitter,
+		Banners:   cfg.Banners,
+	}
+}
+
+func (s Settings) config() types.Config {
+	return types.Config{
+		Mode:      s.Mode,
+		Timeout:   s.Timeout,
+		ScanDelay: s.ScanDelay,
+		MaxJitter: s.MaxJitter,
+		Banners:   s.Banners,
+		Quiet:     true,
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", contentTypeJSON)
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, errorResponse{Error: err.Error()})
+}
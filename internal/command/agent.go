@@ -0,0 +1,70 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"port-scanner/internal/cluster"
+	"port-scanner/internal/config"
+	"syscall"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	missingCoordinatorError = errors.New(`required flag(s) "coordinator" not set`)
+)
+
+var (
+	agentCmd = &cobra.Command{
+		Use:   "agent",
+		Short: "Run batches of a coordinator's scan until it is finished",
+		Example: "port-scanner agent --coordinator http://10.0.0.2:8080 --token secret\n" +
+			"PORT_SCANNER_COORDINATOR=http://10.0.0.2:8080 port-scanner agent",
+		Args: cobra.NoArgs,
+		RunE: runAgent,
+	}
+)
+
+func init() {
+	defaults := config.Default()
+	agentCmd.Flags().String("coordinator", defaults.Coordinator, "url of the coordinator: http://10.0.0.2:8080")
+	agentCmd.Flags().String("token", defaults.Token, "bearer token expected by the coordinator")
+	rootCmd.AddCommand(agentCmd)
+}
+
+func runAgent(cmd *cobra.Command, _ []string) error {
+	loaded, err := config.Load(configFile, cmd.Flags())
+	if err != nil {
+		return fmt.Errorf("config failed: %w", err)
+	}
+	cfg := loaded.Config
+
+	if cfg.Coordinator == "" {
+		return missingCoordinatorError
+	}
+
+	agent, err := cluster.NewAgent(cluster.AgentOptions{
+		Coordinator: cfg.Coordinator,
+		Token:       cfg.Token,
+		Retries:     cluster.DefaultRetries,
+	})
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	_, _ = fmt.Fprintf(os.Stderr, "Agent %s working for %s\n", agent.Name(), cfg.Coordinator)
+	stats, err := agent.Run(ctx)
+	_, _ = fmt.Fprintf(os.Stderr,
+		"Scanned %d probes in %d batches: %d open\n",
+		stats.Scanned, stats.Batches, stats.Open,
+	)
+	if err != nil {
+		return fmt.Errorf("agent failed: %w", err)
+	}
+	return nil
+}
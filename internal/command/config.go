@@ -0,0 +1,61 @@
+package command
+
+import (
+	"fmt"
+	"os"
+	"port-scanner/internal/config"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	headerKey    = "KEY"
+	headerValue  = "VALUE"
+	headerSource = "SOURCE"
+	noConfigFile = "none"
+)
+
+var (
+	configCmd = &cobra.Command{
+		Use:   "config",
+		Short: "Inspect configuration",
+	}
+	configShowCmd = &cobra.Command{
+		Use:   "show",
+		Short: "Print the effective configuration and the source of each value",
+		Args:  cobra.NoArgs,
+		RunE:  runConfigShow,
+	}
+)
+
+func init() {
+	addScanFlags(configShowCmd.Flags())
+	configCmd.AddCommand(configShowCmd)
+	rootCmd.AddCommand(configCmd)
+}
+
+func runConfigShow(cmd *cobra.Command, _ []string) error {
+	loaded, err := config.Load(configFile, cmd.Flags())
+	if err != nil {
+		return fmt.Errorf("config failed: %w", err)
+	}
+
+	file := loaded.File
+	if file == "" {
+		file = noConfigFile
+	}
+	_, _ = fmt.Fprintf(os.Stdout, "config file: %s\n\n", file)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", headerKey, headerValue, headerSource)
+	for _, s := range loaded.Settings() {
+		source := string(s.Source)
+		if s.Source == config.SourceEnv {
+			source = fmt.Sprintf("%s (%s)", s.Source, config.EnvName(s.Key))
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", s.Key, s.Value, source)
+	}
+
+	return w.Flush()
+}
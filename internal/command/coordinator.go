@@ -0,0 +1,55 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"port-scanner/internal/cluster"
+	"port-scanner/internal/config"
+	"port-scanner/internal/scanner"
+	"port-scanner/internal/types"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	coordinatorCmd = &cobra.Command{
+		Use:   "coordinator",
+		Short: "Split a scan into batches run by agents and export the merged results",
+		Example: "port-scanner coordinator -a 10.0.0.0/20 -p 1-1024 --listen :8080 --token secret -o results -f json\n" +
+			"port-scanner agent --coordinator http://10.0.0.2:8080 --token secret",
+		Args: cobra.NoArgs,
+		RunE: runCoordinator,
+	}
+)
+
+func init() {
+	defaults := config.Default()
+	addScanFlags(coordinatorCmd.Flags())
+	coordinatorCmd.Flags().String("listen", defaults.Listen, "address agents connect to")
+	coordinatorCmd.Flags().String("token", defaults.Token, "bearer token required from agents")
+	coordinatorCmd.Flags().Int("batch-size", defaults.BatchSize, "probes per batch handed to an agent")
+	coordinatorCmd.Flags().Int("lease-timeout", defaults.LeaseTimeout, "seconds before a batch not returned by its agent is handed to another")
+	rootCmd.AddCommand(coordinatorCmd)
+}
+
+func runCoordinator(cmd *cobra.Command, _ []string) error {
+	return runScan(cmd, coordinate)
+}
+
+func coordinate(ctx context.Context, cfg types.Config, reporters ...scanner.Reporter) (types.Report, error) {
+	listener, err := net.Listen("tcp", cfg.Listen)
+	if err != nil {
+		return types.Report{}, err
+	}
+	_, _ = fmt.Fprintf(os.Stderr, "Waiting for agents on http://%s\n", listener.Addr())
+
+	opts := cluster.Options{
+		Token:        cfg.Token,
+		BatchSize:    cfg.BatchSize,
+		LeaseTimeout: time.Duration(cfg.LeaseTimeout) * time.Second,
+	}
+	return cluster.Coordinate(ctx, listener, cfg, opts, scanner.ScanReporter(cfg, reporters...))
+}
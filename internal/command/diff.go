@@ -0,0 +1,67 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"port-scanner/internal/diff"
+	"port-scanner/internal/output"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	differencesFoundError = errors.New("differences found")
+)
+
+var (
+	diffFormat string
+	diffCmd    = &cobra.Command{
+		Use:   "diff OLD NEW",
+		Short: "Compare two result files",
+		Long: "Compare two result files exported in txt, json or csv format and report newly opened,\n" +
+			"newly closed and unchanged ports per host. Exits with status 2 when differences exist.",
+		Example:      "port-scanner diff old.json new.json\nport-scanner diff old.csv new.csv -f md",
+		Args:         cobra.ExactArgs(2),
+		SilenceUsage: true,
+		RunE:         runDiff,
+	}
+)
+
+func init() {
+	diffCmd.Flags().StringVarP(&diffFormat, "format", "f", string(diff.FormatText), "text, json, md")
+	rootCmd.AddCommand(diffCmd)
+}
+
+func runDiff(cmd *cobra.Command, args []string) error {
+	format, err := diff.ParseFormat(diffFormat)
+	if err != nil {
+		return err
+	}
+
+	previous, err := output.Load(args[0])
+	if err != nil {
+		return err
+	}
+
+	current, err := output.Load(args[1])
+	if err != nil {
+		return err
+	}
+
+	report := diff.Compare(previous, current)
+	rendered, err := diff.Render(report, format)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprint(cmd.OutOrStdout(), rendered)
+	if err != nil {
+		return err
+	}
+
+	if report.HasChanges() {
+		return silentExit(differencesFoundError, exitCodeChanges)
+	}
+
+	return nil
+}
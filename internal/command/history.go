@@ -0,0 +1,216 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"port-scanner/internal/config"
+	"port-scanner/internal/history"
+	"port-scanner/internal/output"
+	"port-scanner/internal/types"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	historyTimeFormat = "2006-01-02 15:04:05"
+	headerID          = "ID"
+	headerStart       = "START"
+	headerDuration    = "DURATION"
+	headerTargets     = "TARGETS"
+	headerPorts       = "PORTS"
+	headerOpen        = "OPEN"
+)
+
+var (
+	missingHistoryError = errors.New(`history database not set: use --history or PORT_SCANNER_HISTORY`)
+)
+
+var (
+	historyCmd = &cobra.Command{
+		Use:   "history",
+		Short: "Browse scans recorded with --history",
+	}
+	historyListCmd = &cobra.Command{
+		Use:     "list",
+		Short:   "List recorded scans, oldest first",
+		Example: "port-scanner history list --history history.db --host 10.0.0.5 --port 8080",
+		Args:    cobra.NoArgs,
+		RunE:    runHistoryList,
+	}
+	historyShowCmd = &cobra.Command{
+		Use:     "show <id|latest>",
+		Short:   "Print a recorded scan and its open ports",
+		Example: "port-scanner history show latest --history history.db",
+		Args:    cobra.ExactArgs(1),
+		RunE:    runHistoryShow,
+	}
+	historyExportCmd = &cobra.Command{
+		Use:     "export <id|latest>",
+		Short:   "Write the results of a recorded scan to stdout in any output format",
+		Example: "port-scanner history export 12 --history history.db -f csv > scan-12.csv",
+		Args:    cobra.ExactArgs(1),
+		RunE:    runHistoryExport,
+	}
+)
+
+func init() {
+	defaults := config.Default()
+	historyCmd.PersistentFlags().String("history", defaults.History, "database file scans were recorded in")
+	historyListCmd.Flags().String("host", "", "only list scans where the port was open on this host")
+	historyListCmd.Flags().Int("port", 0, "only list scans where this port was open")
+	historyListCmd.Flags().Int("limit", 0, "list only the most recent scans")
+	historyExportCmd.Flags().StringP("format", "f", defaults.Format, "txt, json, csv, xml, grep, html, md, ndjson, junit, prom")
+
+	historyCmd.AddCommand(historyListCmd, historyShowCmd, historyExportCmd)
+	rootCmd.AddCommand(historyCmd)
+}
+
+func openHistory(cmd *cobra.Command) (*history.Store, types.Config, error) {
+	loaded, err := config.Load(configFile, cmd.Flags())
+	if err != nil {
+		return nil, types.Config{}, fmt.Errorf("config failed: %w", err)
+	}
+
+	if loaded.Config.History == "" {
+		return nil, types.Config{}, missingHistoryError
+	}
+
+	store, err := history.Open(loaded.Config.History, true)
+	if err != nil {
+		return nil, types.Config{}, err
+	}
+	return store, loaded.Config, nil
+}
+
+func runHistoryList(cmd *cobra.Command, _ []string) error {
+	store, _, err := openHistory(cmd)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = store.Close()
+	}()
+
+	host, _ := cmd.Flags().GetString("host")
+	port, _ := cmd.Flags().GetInt("port")
+	limit, _ := cmd.Flags().GetInt("limit")
+
+	var scans []history.Scan
+	if port > 0 {
+		scans, err = store.Find(host, port)
+	} else {
+		scans, err = store.List()
+	}
+	if err != nil {
+		return err
+	}
+
+	if limit > 0 && len(scans) > limit {
+		scans = scans[len(scans)-limit:]
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", headerID, headerStart, headerDuration, headerTargets, headerPorts, headerOpen)
+	for _, s := range scans {
+		_, _ = fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%d\n",
+			s.ID,
+			s.Metadata.Start.Local().Format(historyTimeFormat),
+			s.Metadata.End.Sub(s.Metadata.Start).Round(time.Millisecond),
+			strings.Join(s.Metadata.Targets, ","),
+			scanPorts(s.Metadata),
+			s.Summary.Open,
+		)
+	}
+	return w.Flush()
+}
+
+func runHistoryShow(cmd *cobra.Command, args []string) error {
+	store, _, err := openHistory(cmd)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = store.Close()
+	}()
+
+	scan, report, err := store.Report(args[0])
+	if err != nil {
+		return err
+	}
+
+	metadata := scan.Metadata
+	_, _ = fmt.Fprintf(os.Stdout, "scan:     %d\n", scan.ID)
+	_, _ = fmt.Fprintf(os.Stdout, "start:    %s\n", metadata.Start.Local().Format(historyTimeFormat))
+	_, _ = fmt.Fprintf(os.Stdout, "duration: %s\n", metadata.End.Sub(metadata.Start).Round(time.Millisecond))
+	_, _ = fmt.Fprintf(os.Stdout, "targets:  %s\n", strings.Join(metadata.Targets, ","))
+	_, _ = fmt.Fprintf(os.Stdout, "ports:    %s\n", scanPorts(metadata))
+	_, _ = fmt.Fprintf(os.Stdout, "mode:     %s\n", metadata.Mode)
+	if len(metadata.Args) > 0 {
+		_, _ = fmt.Fprintf(os.Stdout, "command:  %s\n", strings.Join(metadata.Args, " "))
+	}
+	_, _ = fmt.Fprintf(os.Stdout, "summary:  %d hosts, %d ports (%d probes): %d open\n\n",
+		scan.Summary.Hosts, scan.Summary.Ports, scan.Summary.Scanned, scan.Summary.Open)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "HOST\tPORT\tSERVICE\tBANNER")
+	for _, r := range report.Results {
+		if r.Status {
+			_, _ = fmt.Fprintf(w, "%s\t%d\t%s\t%s\n", r.Host, r.Port, r.Service, r.Banner)
+		}
+	}
+	err = w.Flush()
+	if err != nil {
+		return err
+	}
+
+	for _, v := range report.Violations {
+		_, _ = fmt.Fprintf(os.Stdout, "\n%s: %s", v.Rule, v.Message)
+	}
+	if len(report.Violations) > 0 {
+		_, _ = fmt.Fprintln(os.Stdout)
+	}
+	return nil
+}
+
+func runHistoryExport(cmd *cobra.Command, args []string) error {
+	store, cfg, err := openHistory(cmd)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = store.Close()
+	}()
+
+	_, report, err := store.Report(args[0])
+	if err != nil {
+		return err
+	}
+
+	value, _ := cmd.Flags().GetString("format")
+	format, err := output.ParseFormat(value)
+	if err != nil {
+		return err
+	}
+
+	content, err := output.Render(report, format, cfg)
+	if err != nil {
+		return fmt.Errorf("export failed: %w", err)
+	}
+
+	_, err = fmt.Fprint(os.Stdout, content)
+	return err
+}
+
+func scanPorts(metadata types.Metadata) string {
+	if metadata.Input != "" {
+		return "from " + metadata.Input
+	}
+	if metadata.Ports == "" {
+		return "-"
+	}
+	return metadata.Ports
+}
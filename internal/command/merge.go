@@ -0,0 +1,122 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"port-scanner/internal/config"
+	"port-scanner/internal/merge"
+	"port-scanner/internal/output"
+	"port-scanner/internal/types"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	duplicatesFoundError = errors.New("duplicate results found")
+)
+
+var (
+	mergeCmd = &cobra.Command{
+		Use:   "merge FILE...",
+		Short: "Combine shard exports into one result set",
+		Long: "Combine result files exported in any format, e.g. by scans run with --shard, into one result set.\n" +
+			"Ports found in more than one file are reported and the merge exits with status 2; an open result\n" +
+			"wins over a closed one.",
+		Example:      "port-scanner merge shard-1.json shard-2.json shard-3.json shard-4.json -o merged -f json",
+		Args:         cobra.MinimumNArgs(1),
+		SilenceUsage: true,
+		RunE:         runMerge,
+	}
+)
+
+func init() {
+	defaults := config.Default()
+	flags := mergeCmd.Flags()
+	flags.StringP("output", "o", defaults.Output, "output file name, - for stdout")
+	flags.StringP("format", "f", defaults.Format, "txt, json, csv, xml, grep, html, md, ndjson, junit, prom")
+	flags.StringSlice("out", defaults.Outputs, "additional outputs as format:path, repeatable: json:-,csv:results.csv")
+	flags.Bool("json-legacy", defaults.JsonLegacy, "write json outputs as a bare array of results")
+	flags.Bool("open-only", defaults.OpenOnly, "export open ports only")
+	flags.String("filter", defaults.Filter, `export results matching an expression: status == open && port < 1024`)
+	flags.Bool("ndjson-meta", defaults.NdjsonMeta, "add header and footer records with scan metadata to ndjson outputs")
+	rootCmd.AddCommand(mergeCmd)
+}
+
+func runMerge(cmd *cobra.Command, args []string) error {
+	cfg, _, _, err := loadConfig(cmd)
+	if err != nil {
+		return err
+	}
+
+	inputs := make([]merge.Input, 0, len(args))
+	for _, path := range args {
+		loaded, err := output.LoadReport(path)
+		if err != nil {
+			return err
+		}
+		inputs = append(inputs, merge.Input{Name: path, Results: loaded.Results, Start: loaded.Metadata.Start, End: loaded.Metadata.End})
+	}
+
+	merged := merge.Merge(inputs)
+	summary := merged.Summary()
+	printSummary(summary)
+	printDuplicates(merged)
+
+	start, end := merged.Start, merged.End
+	if start.IsZero() || end.IsZero() {
+		now := time.Now()
+		start, end = now, now
+	}
+
+	report := types.Report{
+		Results: merged.Results,
+		Summary: summary,
+		Metadata: types.Metadata{
+			Version: version,
+			Args:    os.Args,
+			Targets: resultHosts(merged.Results),
+			Start:   start,
+			End:     end,
+		},
+	}
+
+	err = output.Export(report, cfg)
+	if err != nil {
+		return fmt.Errorf("export failed: %w", err)
+	}
+
+	if len(merged.Duplicates) > 0 {
+		return silentExit(fmt.Errorf("%w: %d", duplicatesFoundError, len(merged.Duplicates)), exitCodeChanges)
+	}
+	return nil
+}
+
+func printDuplicates(merged merge.Merged) {
+	if len(merged.Duplicates) == 0 {
+		return
+	}
+
+	_, _ = fmt.Fprintf(os.Stderr, "Duplicates: %d, %d conflicting\n", len(merged.Duplicates), merged.Conflicts())
+	for _, d := range merged.Duplicates {
+		conflict := ""
+		if d.Conflict {
+			conflict = " (conflicting)"
+		}
+		_, _ = fmt.Fprintf(os.Stderr, "  %s:%d in %s%s\n", d.Host, d.Port, strings.Join(d.Sources, ", "), conflict)
+	}
+}
+
+func resultHosts(results []types.Result) []string {
+	hosts := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, r := range results {
+		if r.Host != "" && !seen[r.Host] {
+			seen[r.Host] = true
+			hosts = append(hosts, r.Host)
+		}
+	}
+	return hosts
+}
@@ -1,46 +1,125 @@
 package command
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"port-scanner/internal/config"
+	"port-scanner/internal/history"
+	"port-scanner/internal/notify"
 	"port-scanner/internal/output"
+	"port-scanner/internal/policy"
 	"port-scanner/internal/scanner"
 	"port-scanner/internal/types"
 	"port-scanner/internal/utils"
 	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 var (
-	cfg     types.Config
-	rootCmd = &cobra.Command{
-		Use:     "port-scanner",
-		Short:   "Port Scanner",
-		Example: getExamples(),
-		Version: "1.0.0",
-		RunE:    run,
+	configFile string
+	rootCmd    = &cobra.Command{
+		Use:           "port-scanner",
+		Short:         "Port Scanner",
+		Example:       getExamples(),
+		Version:       version,
+		SilenceErrors: true,
+		RunE:          run,
 	}
 )
 
+const (
+	version            = "1.0.0"
+	exitCodeError      = 1
+	exitCodeChanges    = 2
+	exitCodeViolations = 3
+)
+
+type scanFunc func(ctx context.Context, cfg types.Config, reporters ...scanner.Reporter) (types.Report, error)
+
+type exitError struct {
+	err  error
+	code int
+}
+
+var (
+	missingAddressError  = errors.New(`required flag(s) "address" not set`)
+	violationsFoundError = errors.New("policy violations found")
+)
+
 func init() {
-	rootCmd.Flags().StringVarP(&cfg.Address, "address", "a", "", "domain or ip address")
-	rootCmd.Flags().StringVarP(&cfg.Ports, "ports", "p", "1-65535", "range: 1-1024 or list: 80,443")
-	rootCmd.Flags().StringVarP(&cfg.Mode, "mode", "m", "default", "stealth, default, rapid")
-	rootCmd.Flags().StringVarP(&cfg.Output, "output", "o", "", "output file name")
-	rootCmd.Flags().StringVarP(&cfg.Format, "format", "f", "txt", "txt, json, csv")
-	rootCmd.Flags().IntVarP(&cfg.Timeout, "timeout", "t", 0, "timeout per port in milliseconds")
-	_ = rootCmd.MarkFlagRequired("address")
+	rootCmd.PersistentFlags().StringVar(&configFile, "config", "", "config file (default ~/.config/port-scanner/config.yaml)")
+	addScanFlags(rootCmd.Flags())
+}
+
+func addScanFlags(flags *pflag.FlagSet) {
+	defaults := config.Default()
+	flags.StringP("address", "a", defaults.Address, "domains, ip addresses or cidrs: 10.0.0.1,10.0.1.0/24")
+	flags.StringP("ports", "p", defaults.Ports, "range: 1-1024 or list: 80,443")
+	flags.String("input-results", defaults.InputResults, "scan the hosts and ports of an earlier json, csv, txt, ndjson or nmap xml export instead of -a/-p")
+	flags.Bool("input-open-only", defaults.InputOpenOnly, "only rescan the ports that were open in --input-results")
+	flags.StringP("mode", "m", defaults.Mode, "stealth, default, rapid or timing template paranoid|sneaky|polite|normal|aggressive|insane (t0-t5)")
+	flags.StringP("output", "o", defaults.Output, "output file name, - for stdout")
+	flags.StringP("format", "f", defaults.Format, "txt, json, csv, xml, grep, html, md, ndjson, junit, prom")
+	flags.StringSlice("out", defaults.Outputs, "additional outputs as format:path, repeatable: json:-,csv:results.csv")
+	flags.Bool("json-legacy", defaults.JsonLegacy, "write json outputs as a bare array of results")
+	flags.Bool("open-only", defaults.OpenOnly, "export open ports only")
+	flags.String("filter", defaults.Filter, `export results matching an expression: status == open && port < 1024`)
+	flags.Bool("ndjson-meta", defaults.NdjsonMeta, "add header and footer records with scan metadata to ndjson outputs")
+	flags.String("expectations", defaults.Expectations, "yaml file with the ports expected to be open per host for junit outputs")
+	flags.String("history", defaults.History, "database file to record every scan and its results in")
+	flags.String("policy", defaults.Policy, "yaml file with the allowed, required ports and forbidden services per host or cidr; services are named by port number")
+	flags.IntP("timeout", "t", defaults.Timeout, "timeout per port in milliseconds")
+	flags.Int("scan-delay", defaults.ScanDelay, "delay between probes of each worker in milliseconds, -1 for the mode's delay")
+	flags.Int("max-jitter", defaults.MaxJitter, "maximum random delay added to scan delay in milliseconds, -1 for the mode's jitter")
+	flags.Bool("banners", defaults.Banners, "read service banners from open ports")
+	flags.StringSlice("exclude", defaults.Exclude, "hosts or cidrs never to scan")
+	flags.String("exclude-file", defaults.ExcludeFile, "file with hosts or cidrs never to scan, one per line")
+	flags.String("exclude-ports", defaults.ExcludePorts, "ports never to scan: 22,3306,5432-5433")
+	flags.String("shard", defaults.Shard, "scan only shard i of n of the host and port combinations: 2/4")
+	flags.Int("shard-seed", defaults.ShardSeed, "spread probes pseudo-randomly across shards, same seed on every shard")
+	flags.StringSlice("webhook", defaults.Webhooks, "urls to post scan results to, prefixed with slack: or teams: for chat messages")
+	flags.String("webhook-secret", defaults.WebhookSecret, "key to sign webhook bodies with hmac-sha256")
+	flags.Int("webhook-timeout", defaults.WebhookTimeout, "timeout per webhook request in milliseconds")
+	flags.Int("webhook-retries", defaults.WebhookRetries, "retries of failed webhook requests")
+	flags.String("progress", defaults.Progress, "auto, bar, plain, json, none")
+	flags.BoolP("quiet", "q", defaults.Quiet, "disable progress output")
 }
 
 func Execute() {
 	err := rootCmd.Execute()
 	if err != nil {
-		_, _ = fmt.Fprintln(os.Stderr, "Error:", err)
-		os.Exit(1)
+		var exit exitError
+		if !errors.As(err, &exit) {
+			_, _ = fmt.Fprintln(os.Stderr, "Error:", err)
+		}
+		os.Exit(exitCode(err))
 	}
 }
 
+func exitCode(err error) int {
+	var exit exitError
+	if errors.As(err, &exit) {
+		return exit.code
+	}
+	return exitCodeError
+}
+
+func silentExit(err error, code int) error {
+	return exitError{err: err, code: code}
+}
+
+func (e exitError) Error() string {
+	return e.err.Error()
+}
+
+func (e exitError) Unwrap() error {
+	return e.err
+}
+
 func getExamples() string {
 	if utils.IsDockerized() {
 		return strings.Join([]string{
@@ -54,19 +133,157 @@ func getExamples() string {
 		"port-scanner -a 192.168.1.134",
 		"port-scanner -a 192.168.1.134 -p 1-1024 -m stealth",
 		"port-scanner -a 192.168.1.134 -p 80,443 -o results -f json",
+		"port-scanner -a 192.168.1.134 -o - -f json --quiet | jq '.[] | select(.status)'",
+		"port-scanner -a 192.168.1.134 --out json:results.json --out csv:results.csv",
+		"port-scanner -a 192.168.1.134 --filter 'status == open && port < 1024'",
+		"port-scanner --input-results results.xml --input-open-only -o rescan -f json",
+		"port-scanner -a 10.0.0.0/24 -p 1-1024 --policy policy.yaml --out junit:policy",
+		"port-scanner -a 10.0.0.0/24 -p 1-1024 --exclude 10.0.0.5,10.0.0.128/28 --exclude-ports 3306",
+		"port-scanner -a 10.0.0.0/16 -p 1-1024 --shard 2/4 -o shard-2 -f json",
+		"port-scanner -a 10.0.0.0/24 --webhook slack:https://hooks.slack.com/services/T000/B000/XXXX",
+		"port-scanner -a 192.168.1.134 -m stealth --scan-delay 2000 --max-jitter 1000",
+		"PORT_SCANNER_MODE=rapid port-scanner -a 192.168.1.134",
+		"port-scanner watch -a 192.168.1.134 -p 1-1024 --interval 600 --state state.json",
+		"port-scanner -a 192.168.1.134 -p 1-1024 --history ~/.local/share/port-scanner/history.db",
+		"port-scanner history list --port 8080 --host 192.168.1.134",
+		"port-scanner diff old.json new.json -f md",
+		"port-scanner merge shard-1.json shard-2.json -o merged -f json",
+		"port-scanner serve --listen 127.0.0.1:8080",
+		"port-scanner coordinator -a 10.0.0.0/20 -p 1-1024 --listen :8080 --token secret -o results -f json",
+		"port-scanner agent --coordinator http://10.0.0.2:8080 --token secret",
+		"port-scanner config show --config ./config.yaml",
 	}, "\n")
 }
 
-func run(_ *cobra.Command, _ []string) error {
-	results, err := scanner.Scan(cfg)
+func loadConfig(cmd *cobra.Command) (types.Config, policy.Policy, *notify.Notifier, error) {
+	loaded, err := config.Load(configFile, cmd.Flags())
+	if err != nil {
+		return types.Config{}, policy.Policy{}, nil, fmt.Errorf("config failed: %w", err)
+	}
+
+	_, err = output.ParseFilter(loaded.Config.Filter)
+	if err != nil {
+		return types.Config{}, policy.Policy{}, nil, err
+	}
+
+	_, err = output.Destinations(loaded.Config)
+	if err != nil {
+		return types.Config{}, policy.Policy{}, nil, err
+	}
+
+	_, err = output.LoadExpectations(loaded.Config.Expectations)
+	if err != nil {
+		return types.Config{}, policy.Policy{}, nil, err
+	}
+
+	pol, err := policy.Load(loaded.Config.Policy)
+	if err != nil {
+		return types.Config{}, policy.Policy{}, nil, err
+	}
+
+	_, err = scanner.ParseShard(loaded.Config.Shard, loaded.Config.ShardSeed)
+	if err != nil {
+		return types.Config{}, policy.Policy{}, nil, err
+	}
+
+	notifier, err := notify.New(loaded.Config, version)
+	if err != nil {
+		return types.Config{}, policy.Policy{}, nil, err
+	}
+
+	return loaded.Config, pol, notifier, nil
+}
+
+func run(cmd *cobra.Command, _ []string) error {
+	return runScan(cmd, scanner.Scan)
+}
+
+func runScan(cmd *cobra.Command, scan scanFunc) error {
+	cfg, pol, notifier, err := loadConfig(cmd)
+	if err != nil {
+		return err
+	}
+
+	if cfg.Address == "" && cfg.InputResults == "" {
+		return missingAddressError
+	}
+
+	metadata := types.Metadata{Version: version, Args: os.Args}
+	stream, err := output.OpenStream(cfg, metadata)
+	if err != nil {
+		return fmt.Errorf("export failed: %w", err)
+	}
+	defer func() {
+		_ = stream.Close()
+	}()
+
+	report, err := scan(cmd.Context(), cfg, stream)
 	if err != nil {
 		return fmt.Errorf("scan failed: %w", err)
 	}
+	printSummary(report.Summary)
+
+	report.Violations = pol.Evaluate(report.Results)
+	printViolations(report.Violations)
+	stream.Violations(report.Violations)
+
+	report.Metadata.Version = metadata.Version
+	report.Metadata.Args = metadata.Args
+	err = output.Export(report, cfg, stream.Destinations()...)
+	if err != nil {
+		return fmt.Errorf("export failed: %w", err)
+	}
 
-	err = output.Export(results, cfg)
+	err = stream.Close()
 	if err != nil {
 		return fmt.Errorf("export failed: %w", err)
 	}
 
+	if cfg.History != "" {
+		_, err = history.Record(cfg.History, report)
+		if err != nil {
+			return fmt.Errorf("history failed: %w", err)
+		}
+	}
+
+	err = notifier.Completed(cmd.Context(), report)
+	if err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, "Error:", err)
+	}
+
+	if len(report.Violations) > 0 {
+		cmd.SilenceUsage = true
+		return silentExit(fmt.Errorf("%w: %d", violationsFoundError, len(report.Violations)), exitCodeViolations)
+	}
+
 	return nil
 }
+
+func printSummary(summary types.Summary) {
+	_, _ = fmt.Fprintf(os.Stderr,
+		"Scanned %d hosts, %d ports (%d probes): %d open\n",
+		summary.Hosts, summary.Ports, summary.Scanned, summary.Open,
+	)
+
+	if summary.ExcludedHosts > 0 || summary.ExcludedPorts > 0 {
+		_, _ = fmt.Fprintf(os.Stderr,
+			"Excluded %d hosts, %d ports\n",
+			summary.ExcludedHosts, summary.ExcludedPorts,
+		)
+	}
+
+	if summary.Skipped > 0 {
+		_, _ = fmt.Fprintf(os.Stderr, "Skipped %d probes after the host timeout\n", summary.Skipped)
+	}
+}
+
+func printViolations(violations []types.Violation) {
+	if len(violations) == 0 {
+		return
+	}
+
+	_, _ = fmt.Fprintf(os.Stderr, "Policy violations: %d\n", len(violations))
+	for _, v := range violations {
+		_, _ = fmt.Fprintf(os.Stderr, "  %s: %s\n", v.Rule, v.Message)
+	}
+}
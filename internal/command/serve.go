@@ -0,0 +1,55 @@
+package command
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"port-scanner/internal/config"
+	"port-scanner/internal/server"
+	"syscall"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	serveCmd = &cobra.Command{
+		Use:   "serve",
+		Short: "Run scans submitted over an HTTP API",
+		Example: "port-scanner serve --listen 127.0.0.1:8080\n" +
+			"PORT_SCANNER_TOKEN=secret port-scanner serve --listen :8080 --exclude 10.0.0.1 -m polite",
+		Args: cobra.NoArgs,
+		RunE: runServe,
+	}
+)
+
+func init() {
+	defaults := config.Default()
+	addScanFlags(serveCmd.Flags())
+	serveCmd.Flags().String("listen", defaults.Listen, "address to serve the api on")
+	serveCmd.Flags().String("token", defaults.Token, "bearer token required by every request")
+	rootCmd.AddCommand(serveCmd)
+}
+
+func runServe(cmd *cobra.Command, _ []string) error {
+	cfg, _, _, err := loadConfig(cmd)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	listener, err := net.Listen("tcp", cfg.Listen)
+	if err != nil {
+		return fmt.Errorf("serve failed: %w", err)
+	}
+	_, _ = fmt.Fprintf(os.Stderr, "Listening on http://%s\n", listener.Addr())
+
+	srv := server.New(server.Options{Config: cfg, Token: cfg.Token, Version: version})
+	err = srv.Serve(ctx, listener)
+	if err != nil {
+		return fmt.Errorf("serve failed: %w", err)
+	}
+	return nil
+}
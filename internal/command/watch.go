@@ -0,0 +1,163 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"port-scanner/internal/config"
+	"port-scanner/internal/diff"
+	"port-scanner/internal/history"
+	"port-scanner/internal/metrics"
+	"port-scanner/internal/notify"
+	"port-scanner/internal/output"
+	"port-scanner/internal/policy"
+	"port-scanner/internal/scanner"
+	"port-scanner/internal/types"
+	"port-scanner/internal/watch"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	invalidIntervalError = errors.New("invalid interval: expected a positive number of seconds")
+)
+
+var (
+	watchCmd = &cobra.Command{
+		Use:   "watch",
+		Short: "Rescan on an interval and report port changes",
+		Example: "port-scanner watch -a 192.168.1.134 -p 1-1024 --interval 600 --state state.json\n" +
+			"port-scanner watch -a 192.168.1.134 --banners --on-change ./alert.sh\n" +
+			"port-scanner watch -a 10.0.0.0/24 -p 1-1024 --metrics-listen :9115",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE:         runWatch,
+	}
+)
+
+func init() {
+	defaults := config.Default()
+	addScanFlags(watchCmd.Flags())
+	watchCmd.Flags().Int("interval", defaults.Interval, "seconds between scans")
+	watchCmd.Flags().String("state", defaults.State, "file to persist the last scan between runs")
+	watchCmd.Flags().String("on-change", defaults.OnChange, "shell command run with change events on stdin")
+	watchCmd.Flags().Bool("exit-on-change", defaults.ExitOnChange, "exit with status 2 after the first change")
+	watchCmd.Flags().String("metrics-listen", defaults.MetricsListen, "address to serve prometheus metrics on: :9115")
+	rootCmd.AddCommand(watchCmd)
+}
+
+func runWatch(cmd *cobra.Command, _ []string) error {
+	cfg, pol, notifier, err := loadConfig(cmd)
+	if err != nil {
+		return err
+	}
+
+	if cfg.Address == "" && cfg.InputResults == "" {
+		return missingAddressError
+	}
+
+	if cfg.Interval <= 0 {
+		return invalidIntervalError
+	}
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var server *metrics.Server
+	if cfg.MetricsListen != "" {
+		listener, err := net.Listen("tcp", cfg.MetricsListen)
+		if err != nil {
+			return fmt.Errorf("metrics failed: %w", err)
+		}
+
+		server = metrics.NewServer()
+		go func() {
+			err := server.Serve(ctx, listener)
+			if err != nil {
+				_, _ = fmt.Fprintln(os.Stderr, "Error: metrics failed:", err)
+			}
+		}()
+	}
+
+	err = watch.Run(ctx, watch.Options{
+		Config:       cfg,
+		Interval:     time.Duration(cfg.Interval) * time.Second,
+		State:        cfg.State,
+		Hook:         cfg.OnChange,
+		ExitOnChange: cfg.ExitOnChange,
+		Scan:         scanResults(server, pol, cfg.History),
+		OnChange:     handleChanges(ctx, exportChanges(cfg), notifier),
+		Events:       os.Stdout,
+		Errors:       os.Stderr,
+	})
+	if errors.Is(err, watch.ChangesDetectedError) {
+		return silentExit(err, exitCodeChanges)
+	}
+	return err
+}
+
+func scanResults(server *metrics.Server, pol policy.Policy, historyPath string) watch.ScanFunc {
+	return func(ctx context.Context, cfg types.Config) (types.Report, error) {
+		report, err := scanner.Scan(ctx, cfg)
+		if ctx.Err() != nil {
+			return types.Report{}, ctx.Err()
+		}
+
+		report.Metadata.Version = version
+		report.Metadata.Args = os.Args
+		report.Violations = pol.Evaluate(report.Results)
+		if server != nil {
+			server.Observe(report, err)
+		}
+		if err != nil {
+			return types.Report{}, err
+		}
+
+		if historyPath != "" {
+			_, err = history.Record(historyPath, report)
+			if err != nil {
+				return types.Report{}, fmt.Errorf("history failed: %w", err)
+			}
+		}
+		return report, nil
+	}
+}
+
+func exportChanges(cfg types.Config) watch.ChangeFunc {
+	return func(report types.Report, _ []watch.Event) error {
+		if cfg.Output == "" && len(cfg.Outputs) == 0 {
+			return nil
+		}
+
+		err := output.Export(report, cfg)
+		if err != nil {
+			return fmt.Errorf("export failed: %w", err)
+		}
+		return nil
+	}
+}
+
+func handleChanges(ctx context.Context, export watch.ChangeFunc, notifier *notify.Notifier) watch.ChangeFunc {
+	return func(report types.Report, events []watch.Event) error {
+		err := export(report, events)
+		if err != nil {
+			return err
+		}
+
+		changes := make([]diff.Change, 0, len(events))
+		for _, e := range events {
+			changes = append(changes, e.Change)
+		}
+
+		err = notifier.Opened(ctx, report.Results, changes)
+		if err != nil {
+			_, _ = fmt.Fprintln(os.Stderr, "Error:", err)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,323 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"port-scanner/internal/types"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+type Source string
+
+const (
+	SourceDefault Source = "default"
+	SourceFile    Source = "file"
+	SourceEnv     Source = "env"
+	SourceFlag    Source = "flag"
+)
+
+const (
+	envPrefix       = "PORT_SCANNER_"
+	keyTag          = "yaml"
+	listSeparator   = ","
+	configDirectory = ".config/port-scanner"
+	configFileName  = "config.yaml"
+	maskedValue     = "********"
+)
+
+var (
+	readConfigError  = errors.New("failed to read config file")
+	parseConfigError = errors.New("failed to parse config file")
+	secretKeys       = []string{"token", "webhook-secret"}
+)
+
+type Loaded struct {
+	Config  types.Config
+	File    string
+	Sources map[string]Source
+}
+
+type Setting struct {
+	Key    string
+	Value  string
+	Source Source
+}
+
+func Default() types.Config {
+	return types.Config{
+		Ports:          "1-65535",
+		Mode:           "default",
+		ScanDelay:      -1,
+		MaxJitter:      -1,
+		Format:         "txt",
+		Interval:       300,
+		Progress:       "auto",
+		Listen:         "127.0.0.1:8080",
+		WebhookTimeout: 5000,
+		WebhookRetries: 3,
+		BatchSize:      256,
+		LeaseTimeout:   300,
+	}
+}
+
+func DefaultPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, configDirectory, configFileName)
+}
+
+func Keys() []string {
+	t := reflect.TypeOf(types.Config{})
+	keys := make([]string, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		if key := fieldKey(t.Field(i)); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+func EnvName(key string) string {
+	return envPrefix + strings.ToUpper(strings.ReplaceAll(key, "-", "_"))
+}
+
+func Load(path string, flags *pflag.FlagSet) (Loaded, error) {
+	loaded := Loaded{
+		Config:  Default(),
+		Sources: make(map[string]Source),
+	}
+	for _, key := range Keys() {
+		loaded.Sources[key] = SourceDefault
+	}
+
+	err := loadFile(&loaded, path)
+	if err != nil {
+		return Loaded{}, err
+	}
+
+	err = loadEnv(&loaded)
+	if err != nil {
+		return Loaded{}, err
+	}
+
+	err = loadFlags(&loaded, flags)
+	if err != nil {
+		return Loaded{}, err
+	}
+
+	return loaded, nil
+}
+
+func Decode(data []byte, v any) error {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	err := decoder.Decode(v)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	return nil
+}
+
+func (l Loaded) Settings() []Setting {
+	v := reflect.ValueOf(l.Config)
+	t := v.Type()
+	settings := make([]Setting, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		key := fieldKey(t.Field(i))
+		if key == "" {
+			continue
+		}
+		value := formatValue(v.Field(i))
+		if value != "" && slices.Contains(secretKeys, key) {
+			value = maskedValue
+		}
+		settings = append(settings, Setting{
+			Key:    key,
+			Value:  value,
+			Source: l.Sources[key],
+		})
+	}
+	return settings
+}
+
+func loadFile(loaded *Loaded, path string) error {
+	explicit := path != ""
+	if !explicit {
+		path = DefaultPath()
+		if path == "" {
+			return nil
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !explicit && errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("%w: %s", readConfigError, path)
+	}
+
+	values := make(map[string]any)
+	err = yaml.Unmarshal(data, &values)
+	if err != nil {
+		return fmt.Errorf("%w: %s: %v", parseConfigError, path, err)
+	}
+
+	err = Apply(&loaded.Config, values)
+	if err != nil {
+		return fmt.Errorf("%w: %s: %v", parseConfigError, path, err)
+	}
+
+	for key := range values {
+		loaded.Sources[key] = SourceFile
+	}
+
+	loaded.File = path
+	return nil
+}
+
+func loadEnv(loaded *Loaded) error {
+	for _, key := range Keys() {
+		value, ok := os.LookupEnv(EnvName(key))
+		if !ok {
+			continue
+		}
+
+		err := set(&loaded.Config, key, value)
+		if err != nil {
+			return fmt.Errorf("invalid %s: %w", EnvName(key), err)
+		}
+		loaded.Sources[key] = SourceEnv
+	}
+	return nil
+}
+
+func loadFlags(loaded *Loaded, flags *pflag.FlagSet) error {
+	if flags == nil {
+		return nil
+	}
+
+	var err error
+	flags.Visit(func(f *pflag.Flag) {
+		if err != nil || !hasKey(f.Name) {
+			return
+		}
+
+		value := f.Value.String()
+		if slice, ok := f.Value.(pflag.SliceValue); ok {
+			value = strings.Join(slice.GetSlice(), listSeparator)
+		}
+
+		err = set(&loaded.Config, f.Name, value)
+		if err != nil {
+			err = fmt.Errorf("invalid --%s: %w", f.Name, err)
+			return
+		}
+		loaded.Sources[f.Name] = SourceFlag
+	})
+	return err
+}
+
+func Apply(cfg *types.Config, values map[string]any) error {
+	for key, value := range values {
+		err := set(cfg, key, yamlString(value))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func hasKey(key string) bool {
+	for _, k := range Keys() {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+func set(cfg *types.Config, key, value string) error {
+	v := reflect.ValueOf(cfg).Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if fieldKey(t.Field(i)) == key {
+			return parseValue(v.Field(i), value)
+		}
+	}
+	return fmt.Errorf("unknown key: %q", key)
+}
+
+func parseValue(field reflect.Value, value string) error {
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Int:
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			return fmt.Errorf("expected integer, got %q", value)
+		}
+		field.SetInt(int64(n))
+	case reflect.Bool:
+		b, err := strconv.ParseBool(strings.TrimSpace(value))
+		if err != nil {
+			return fmt.Errorf("expected boolean, got %q", value)
+		}
+		field.SetBool(b)
+	case reflect.Slice:
+		items := make([]string, 0)
+		for _, item := range strings.Split(value, listSeparator) {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		field.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported type: %s", field.Kind())
+	}
+	return nil
+}
+
+func formatValue(field reflect.Value) string {
+	if field.Kind() == reflect.Slice {
+		return strings.Join(field.Interface().([]string), listSeparator)
+	}
+	return fmt.Sprint(field.Interface())
+}
+
+func yamlString(value any) string {
+	if value == nil {
+		return ""
+	}
+
+	list, ok := value.([]any)
+	if !ok {
+		return fmt.Sprint(value)
+	}
+
+	items := make([]string, 0, len(list))
+	for _, item := range list {
+		items = append(items, fmt.Sprint(item))
+	}
+	return strings.Join(items, listSeparator)
+}
+
+func fieldKey(field reflect.StructField) string {
+	key, _, _ := strings.Cut(field.Tag.Get(keyTag), ",")
+	if key == "-" {
+		return ""
+	}
+	return key
+}
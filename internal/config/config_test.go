@@ -0,0 +1,281 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"port-scanner/internal/types"
+	"reflect"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+func newFlagSet(args ...string) *pflag.FlagSet {
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.StringP("address", "a", "", "")
+	flags.StringP("ports", "p", "1-65535", "")
+	flags.StringP("mode", "m", "default", "")
+	flags.IntP("timeout", "t", 0, "")
+	flags.Int("scan-delay", -1, "")
+	flags.Int("max-jitter", -1, "")
+	flags.String("config", "", "")
+	_ = flags.Parse(args)
+	return flags
+}
+
+func withDefaults(set func(c *types.Config)) types.Config {
+	cfg := Default()
+	set(&cfg)
+	return cfg
+}
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	file := writeConfig(t, "address: 10.0.0.1\nports: 1-1024\nmode: stealth\ntimeout: 250\n")
+
+	tests := []struct {
+		name     string
+		file     string
+		env      map[string]string
+		args     []string
+		expected types.Config
+		sources  map[string]Source
+	}{
+		{
+			name:     "defaults only",
+			expected: Default(),
+			sources:  map[string]Source{"address": SourceDefault, "ports": SourceDefault, "mode": SourceDefault},
+		},
+		{
+			name: "file overrides defaults",
+			file: file,
+			expected: withDefaults(func(c *types.Config) {
+				c.Address, c.Ports, c.Mode, c.Timeout = "10.0.0.1", "1-1024", "stealth", 250
+			}),
+			sources: map[string]Source{"address": SourceFile, "format": SourceDefault, "timeout": SourceFile},
+		},
+		{
+			name: "env overrides file",
+			file: file,
+			env:  map[string]string{"PORT_SCANNER_MODE": "rapid", "PORT_SCANNER_TIMEOUT": "100"},
+			expected: withDefaults(func(c *types.Config) {
+				c.Address, c.Ports, c.Mode, c.Timeout = "10.0.0.1", "1-1024", "rapid", 100
+			}),
+			sources: map[string]Source{"mode": SourceEnv, "timeout": SourceEnv, "ports": SourceFile},
+		},
+		{
+			name: "flags override env",
+			file: file,
+			env:  map[string]string{"PORT_SCANNER_MODE": "rapid"},
+			args: []string{"-m", "default", "-a", "127.0.0.1", "--config", file},
+			expected: withDefaults(func(c *types.Config) {
+				c.Address, c.Ports, c.Timeout = "127.0.0.1", "1-1024", 250
+			}),
+			sources: map[string]Source{"mode": SourceFlag, "address": SourceFlag, "ports": SourceFile},
+		},
+		{
+			name: "zero flags override the unset default",
+			args: []string{"-m", "stealth", "--scan-delay", "0", "--max-jitter", "0"},
+			expected: withDefaults(func(c *types.Config) {
+				c.Mode, c.ScanDelay, c.MaxJitter = "stealth", 0, 0
+			}),
+			sources: map[string]Source{"scan-delay": SourceFlag, "max-jitter": SourceFlag},
+		},
+		{
+			name: "unchanged flags keep lower layers",
+			env:  map[string]string{"PORT_SCANNER_PORTS": "80,443"},
+			args: []string{"-a", "127.0.0.1"},
+			expected: withDefaults(func(c *types.Config) {
+				c.Address, c.Ports = "127.0.0.1", "80,443"
+			}),
+			sources: map[string]Source{"ports": SourceEnv, "mode": SourceDefault},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			loaded, err := Load(tt.file, newFlagSet(tt.args...))
+			if err != nil {
+				t.Fatalf("Load() unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(loaded.Config, tt.expected) {
+				t.Errorf("Load() config = %+v, want %+v", loaded.Config, tt.expected)
+			}
+
+			for key, expected := range tt.sources {
+				if got := loaded.Sources[key]; got != expected {
+					t.Errorf("Load() source[%s] = %q, want %q", key, got, expected)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	tests := []struct {
+		name string
+		file string
+		env  map[string]string
+	}{
+		{
+			name: "missing explicit file",
+			file: filepath.Join(t.TempDir(), "missing.yaml"),
+		},
+		{
+			name: "unknown key in file",
+			file: writeConfig(t, "unknown: value\n"),
+		},
+		{
+			name: "invalid yaml",
+			file: writeConfig(t, "address: [\n"),
+		},
+		{
+			name: "invalid integer in file",
+			file: writeConfig(t, "timeout: fast\n"),
+		},
+		{
+			name: "invalid integer in env",
+			env:  map[string]string{"PORT_SCANNER_TIMEOUT": "fast"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			_, err := Load(tt.file, nil)
+			if err == nil {
+				t.Errorf("Load() expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestLoadDefaultPath(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	path := filepath.Join(home, ".config", "port-scanner", "config.yaml")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("Failed to create config directory: %v", err)
+	}
+	if err := os.WriteFile(path, []byte("format: json\n"), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	loaded, err := Load("", nil)
+	if err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+
+	if loaded.File != path {
+		t.Errorf("Load() file = %q, want %q", loaded.File, path)
+	}
+	if loaded.Config.Format != "json" {
+		t.Errorf("Load() format = %q, want %q", loaded.Config.Format, "json")
+	}
+	if loaded.Sources["format"] != SourceFile {
+		t.Errorf("Load() source[format] = %q, want %q", loaded.Sources["format"], SourceFile)
+	}
+}
+
+func TestEnvName(t *testing.T) {
+	tests := []struct {
+		key      string
+		expected string
+	}{
+		{"address", "PORT_SCANNER_ADDRESS"},
+		{"scan-delay", "PORT_SCANNER_SCAN_DELAY"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if got := EnvName(tt.key); got != tt.expected {
+				t.Errorf("EnvName(%q) = %q, want %q", tt.key, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSettings(t *testing.T) {
+	loaded := Loaded{
+		Config:  types.Config{Address: "127.0.0.1", Timeout: 100, Token: "hunter2", WebhookSecret: "s3cret"},
+		Sources: map[string]Source{"address": SourceFlag, "timeout": SourceEnv, "token": SourceEnv, "webhook-secret": SourceFile},
+	}
+
+	settings := loaded.Settings()
+	if len(settings) != len(Keys()) {
+		t.Fatalf("Settings() length = %d, want %d", len(settings), len(Keys()))
+	}
+
+	for _, s := range settings {
+		switch s.Key {
+		case "address":
+			if s.Value != "127.0.0.1" || s.Source != SourceFlag {
+				t.Errorf("Settings() address = %+v", s)
+			}
+		case "timeout":
+			if s.Value != "100" || s.Source != SourceEnv {
+				t.Errorf("Settings() timeout = %+v", s)
+			}
+		case "token":
+			if s.Value != maskedValue || s.Source != SourceEnv {
+				t.Errorf("Settings() token = %+v, want it masked", s)
+			}
+		case "webhook-secret":
+			if s.Value != maskedValue || s.Source != SourceFile {
+				t.Errorf("Settings() webhook-secret = %+v, want it masked", s)
+			}
+		}
+	}
+}
+
+func TestDecode(t *testing.T) {
+	type rule struct {
+		Open []int `yaml:"open"`
+	}
+
+	tests := []struct {
+		name     string
+		data     string
+		expected []int
+		wantErr  bool
+	}{
+		{"known fields", "open: [22, 80]\n", []int{22, 80}, false},
+		{"empty document", "", nil, false},
+		{"unknown field", "opened: [22]\n", nil, true},
+		{"invalid yaml", "open: [22\n", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var r rule
+			err := Decode([]byte(tt.data), &r)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Decode() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && len(r.Open) != len(tt.expected) {
+				t.Errorf("Decode() open = %v, want %v", r.Open, tt.expected)
+			}
+		})
+	}
+}
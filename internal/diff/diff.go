@@ -0,0 +1,154 @@
+package diff
+
+import (
+	"port-scanner/internal/types"
+	"sort"
+)
+
+type Kind string
+
+const (
+	KindOpened        Kind = "opened"
+	KindClosed        Kind = "closed"
+	KindBannerChanged Kind = "banner_changed"
+)
+
+type Change struct {
+	Kind           Kind   `json:"kind"`
+	Host           string `json:"host,omitempty"`
+	Port           int    `json:"port"`
+	Banner         string `json:"banner,omitempty"`
+	PreviousBanner string `json:"previous_banner,omitempty"`
+}
+
+type HostReport struct {
+	Host      string `json:"host"`
+	Opened    []int  `json:"opened"`
+	Closed    []int  `json:"closed"`
+	Unchanged []int  `json:"unchanged"`
+}
+
+type Report struct {
+	Hosts []HostReport `json:"hosts"`
+}
+
+type endpoint struct {
+	host string
+	port int
+}
+
+func Changes(previous, current []types.Result) []Change {
+	skipped := skippedPorts(previous, current)
+	before := openPorts(previous, skipped)
+	after := openPorts(current, skipped)
+	changes := make([]Change, 0)
+
+	for key, result := range after {
+		old, ok := before[key]
+		switch {
+		case !ok:
+			changes = append(changes, Change{Kind: KindOpened, Host: key.host, Port: key.port, Banner: result.Banner})
+		case old.Banner != "" && result.Banner != "" && old.Banner != result.Banner:
+			changes = append(changes, Change{
+				Kind:           KindBannerChanged,
+				Host:           key.host,
+				Port:           key.port,
+				Banner:         result.Banner,
+				PreviousBanner: old.Banner,
+			})
+		}
+	}
+
+	for key, result := range before {
+		if _, ok := after[key]; !ok {
+			changes = append(changes, Change{Kind: KindClosed, Host: key.host, Port: key.port, PreviousBanner: result.Banner})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Host != changes[j].Host {
+			return changes[i].Host < changes[j].Host
+		}
+		if changes[i].Port != changes[j].Port {
+			return changes[i].Port < changes[j].Port
+		}
+		return changes[i].Kind < changes[j].Kind
+	})
+
+	return changes
+}
+
+func Compare(previous, current []types.Result) Report {
+	skipped := skippedPorts(previous, current)
+	before := openPorts(previous, skipped)
+	after := openPorts(current, skipped)
+	hosts := make(map[string]*HostReport)
+
+	host := func(name string) *HostReport {
+		if _, ok := hosts[name]; !ok {
+			hosts[name] = &HostReport{Host: name, Opened: []int{}, Closed: []int{}, Unchanged: []int{}}
+		}
+		return hosts[name]
+	}
+
+	for key := range after {
+		h := host(key.host)
+		if _, ok := before[key]; ok {
+			h.Unchanged = append(h.Unchanged, key.port)
+		} else {
+			h.Opened = append(h.Opened, key.port)
+		}
+	}
+
+	for key := range before {
+		if _, ok := after[key]; !ok {
+			h := host(key.host)
+			h.Closed = append(h.Closed, key.port)
+		}
+	}
+
+	report := Report{Hosts: make([]HostReport, 0, len(hosts))}
+	for _, h := range hosts {
+		sort.Ints(h.Opened)
+		sort.Ints(h.Closed)
+		sort.Ints(h.Unchanged)
+		report.Hosts = append(report.Hosts, *h)
+	}
+	sort.Slice(report.Hosts, func(i, j int) bool {
+		return report.Hosts[i].Host < report.Hosts[j].Host
+	})
+
+	return report
+}
+
+func (r Report) HasChanges() bool {
+	for _, h := range r.Hosts {
+		if len(h.Opened) > 0 || len(h.Closed) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func openPorts(results []types.Result, skipped map[endpoint]bool) map[endpoint]types.Result {
+	open := make(map[endpoint]types.Result)
+	for _, r := range results {
+		key := endpoint{host: r.Host, port: r.Port}
+		if r.Status && !skipped[key] {
+			open[key] = r
+		}
+	}
+	return open
+}
+
+func skippedPorts(previous, current []types.Result) map[endpoint]bool {
+	skipped := make(map[endpoint]bool)
+	for _, results := range [][]types.Result{previous, current} {
+		for _, r := range results {
+			if r.Skipped {
+				skipped[endpoint{host: r.Host, port: r.Port}] = true
+			}
+		}
+	}
+	return skipped
+}
@@ -0,0 +1,142 @@
+package diff
+
+import (
+	"port-scanner/internal/types"
+	"reflect"
+	"testing"
+)
+
+func TestChanges(t *testing.T) {
+	tests := []struct {
+		name     string
+		previous []types.Result
+		current  []types.Result
+		expected []Change
+	}{
+		{
+			name:     "no results",
+			expected: []Change{},
+		},
+		{
+			name:     "unchanged",
+			previous: []types.Result{{Port: 22, Status: true}, {Port: 80, Status: false}},
+			current:  []types.Result{{Port: 22, Status: true}, {Port: 80, Status: false}},
+			expected: []Change{},
+		},
+		{
+			name:     "port opened",
+			previous: []types.Result{{Port: 22, Status: true}, {Port: 80, Status: false}},
+			current:  []types.Result{{Port: 22, Status: true}, {Port: 80, Status: true, Banner: "nginx"}},
+			expected: []Change{{Kind: KindOpened, Port: 80, Banner: "nginx"}},
+		},
+		{
+			name:     "port closed",
+			previous: []types.Result{{Port: 22, Status: true, Banner: "SSH-2.0-OpenSSH_9.6"}},
+			current:  []types.Result{{Port: 22, Status: false}},
+			expected: []Change{{Kind: KindClosed, Port: 22, PreviousBanner: "SSH-2.0-OpenSSH_9.6"}},
+		},
+		{
+			name:     "port missing from current scan is closed",
+			previous: []types.Result{{Port: 8080, Status: true}},
+			current:  []types.Result{},
+			expected: []Change{{Kind: KindClosed, Port: 8080}},
+		},
+		{
+			name:     "banner changed",
+			previous: []types.Result{{Port: 22, Status: true, Banner: "SSH-2.0-OpenSSH_9.6"}},
+			current:  []types.Result{{Port: 22, Status: true, Banner: "SSH-2.0-OpenSSH_9.7"}},
+			expected: []Change{{Kind: KindBannerChanged, Port: 22, Banner: "SSH-2.0-OpenSSH_9.7", PreviousBanner: "SSH-2.0-OpenSSH_9.6"}},
+		},
+		{
+			name:     "missing banner is not a change",
+			previous: []types.Result{{Port: 22, Status: true, Banner: "SSH-2.0-OpenSSH_9.6"}},
+			current:  []types.Result{{Port: 22, Status: true}},
+			expected: []Change{},
+		},
+		{
+			name:     "skipped port is not closed",
+			previous: []types.Result{{Port: 22, Status: true}, {Port: 80, Status: true}},
+			current:  []types.Result{{Port: 22, Status: false, Skipped: true}, {Port: 80, Status: false}},
+			expected: []Change{{Kind: KindClosed, Port: 80}},
+		},
+		{
+			name:     "port after a skipped scan is not opened",
+			previous: []types.Result{{Port: 22, Status: false, Skipped: true}},
+			current:  []types.Result{{Port: 22, Status: true}},
+			expected: []Change{},
+		},
+		{
+			name:     "changes are sorted by port",
+			previous: []types.Result{{Port: 443, Status: true}, {Port: 21, Status: false}},
+			current:  []types.Result{{Port: 443, Status: false}, {Port: 21, Status: true}},
+			expected: []Change{{Kind: KindOpened, Port: 21}, {Kind: KindClosed, Port: 443}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Changes(tt.previous, tt.current)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Changes() = %+v, want %+v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		name     string
+		previous []types.Result
+		current  []types.Result
+		expected Report
+		changed  bool
+	}{
+		{
+			name:     "no open ports",
+			previous: []types.Result{{Port: 80, Status: false}},
+			current:  []types.Result{{Port: 80, Status: false}},
+			expected: Report{Hosts: []HostReport{}},
+			changed:  false,
+		},
+		{
+			name:     "unchanged",
+			previous: []types.Result{{Host: "10.0.0.1", Port: 22, Status: true}},
+			current:  []types.Result{{Host: "10.0.0.1", Port: 22, Status: true}},
+			expected: Report{Hosts: []HostReport{
+				{Host: "10.0.0.1", Opened: []int{}, Closed: []int{}, Unchanged: []int{22}},
+			}},
+			changed: false,
+		},
+		{
+			name: "opened and closed per host",
+			previous: []types.Result{
+				{Host: "10.0.0.2", Port: 443, Status: true},
+				{Host: "10.0.0.1", Port: 22, Status: true},
+				{Host: "10.0.0.1", Port: 80, Status: true},
+			},
+			current: []types.Result{
+				{Host: "10.0.0.1", Port: 22, Status: true},
+				{Host: "10.0.0.1", Port: 8080, Status: true},
+				{Host: "10.0.0.1", Port: 3306, Status: true},
+				{Host: "10.0.0.2", Port: 443, Status: false},
+			},
+			expected: Report{Hosts: []HostReport{
+				{Host: "10.0.0.1", Opened: []int{3306, 8080}, Closed: []int{80}, Unchanged: []int{22}},
+				{Host: "10.0.0.2", Opened: []int{}, Closed: []int{443}, Unchanged: []int{}},
+			}},
+			changed: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Compare(tt.previous, tt.current)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Compare() = %+v, want %+v", got, tt.expected)
+			}
+			if got.HasChanges() != tt.changed {
+				t.Errorf("HasChanges() = %v, want %v", got.HasChanges(), tt.changed)
+			}
+		})
+	}
+}
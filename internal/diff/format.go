@@ -0,0 +1,112 @@
+package diff
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"port-scanner/internal/hostmatch"
+	"strconv"
+	"strings"
+)
+
+type Format string
+
+const (
+	FormatText     Format = "text"
+	FormatJson     Format = "json"
+	FormatMarkdown Format = "md"
+)
+
+const (
+	emptyList = "-"
+)
+
+var (
+	renderError = errors.New("failed to render report")
+)
+
+func ParseFormat(s string) (Format, error) {
+	switch strings.ToLower(s) {
+	case "text", "txt":
+		return FormatText, nil
+	case "json":
+		return FormatJson, nil
+	case "md", "markdown":
+		return FormatMarkdown, nil
+	default:
+		return "", fmt.Errorf("invalid format: %q", s)
+	}
+}
+
+func Render(report Report, format Format) (string, error) {
+	switch format {
+	case FormatJson:
+		return toJSON(report)
+	case FormatMarkdown:
+		return toMarkdown(report), nil
+	default:
+		return toText(report), nil
+	}
+}
+
+func toJSON(report Report) (string, error) {
+	data, err := json.MarshalIndent(struct {
+		Changed bool `json:"changed"`
+		Report
+	}{report.HasChanges(), report}, "", "  ")
+	if err != nil {
+		return "", renderError
+	}
+	return string(data) + "\n", nil
+}
+
+func toText(report Report) string {
+	var sb strings.Builder
+	if len(report.Hosts) == 0 {
+		sb.WriteString("No open ports in either file\n")
+		return sb.String()
+	}
+
+	for _, h := range report.Hosts {
+		sb.WriteString(fmt.Sprintf("Host: %s\n", hostmatch.Name(h.Host)))
+		sb.WriteString(fmt.Sprintf("  %-10s %s\n", "Opened:", joinPorts(h.Opened)))
+		sb.WriteString(fmt.Sprintf("  %-10s %s\n", "Closed:", joinPorts(h.Closed)))
+		sb.WriteString(fmt.Sprintf("  %-10s %s\n", "Unchanged:", joinPorts(h.Unchanged)))
+	}
+
+	return sb.String()
+}
+
+func toMarkdown(report Report) string {
+	var sb strings.Builder
+	sb.WriteString("## Port changes\n\n")
+	if len(report.Hosts) == 0 {
+		sb.WriteString("No open ports in either file.\n")
+		return sb.String()
+	}
+
+	sb.WriteString("| Host | Opened | Closed | Unchanged |\n")
+	sb.WriteString("| :--- | :----- | :----- | :-------- |\n")
+	for _, h := range report.Hosts {
+		sb.WriteString(fmt.Sprintf("| %s | %s | %s | %s |\n",
+			hostmatch.Name(h.Host),
+			joinPorts(h.Opened),
+			joinPorts(h.Closed),
+			joinPorts(h.Unchanged),
+		))
+	}
+
+	return sb.String()
+}
+
+func joinPorts(ports []int) string {
+	if len(ports) == 0 {
+		return emptyList
+	}
+
+	parts := make([]string, 0, len(ports))
+	for _, p := range ports {
+		parts = append(parts, strconv.Itoa(p))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,98 @@
+package diff
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+var testReport = Report{Hosts: []HostReport{
+	{Host: "10.0.0.1", Opened: []int{8080}, Closed: []int{80}, Unchanged: []int{22, 443}},
+	{Host: "", Opened: []int{}, Closed: []int{}, Unchanged: []int{53}},
+}}
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		input       string
+		expected    Format
+		expectError bool
+	}{
+		{"text", FormatText, false},
+		{"TXT", FormatText, false},
+		{"json", FormatJson, false},
+		{"md", FormatMarkdown, false},
+		{"markdown", FormatMarkdown, false},
+		{"csv", "", true},
+		{"", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseFormat(tt.input)
+			if (err != nil) != tt.expectError {
+				t.Fatalf("ParseFormat(%q) error = %v, wantErr %v", tt.input, err, tt.expectError)
+			}
+			if got != tt.expected {
+				t.Errorf("ParseFormat(%q) = %v, want %v", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name     string
+		format   Format
+		contains []string
+	}{
+		{
+			name:     "text",
+			format:   FormatText,
+			contains: []string{"Host: 10.0.0.1", "Opened:    8080", "Closed:    80", "Unchanged: 22, 443", "Host: -"},
+		},
+		{
+			name:     "markdown",
+			format:   FormatMarkdown,
+			contains: []string{"| Host | Opened | Closed | Unchanged |", "| 10.0.0.1 | 8080 | 80 | 22, 443 |", "| - | - | - | 53 |"},
+		},
+		{
+			name:     "json",
+			format:   FormatJson,
+			contains: []string{`"changed": true`, `"opened": [`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Render(testReport, tt.format)
+			if err != nil {
+				t.Fatalf("Render() unexpected error: %v", err)
+			}
+
+			for _, want := range tt.contains {
+				if !strings.Contains(got, want) {
+					t.Errorf("Render() missing %q in:\n%s", want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderJSON(t *testing.T) {
+	got, err := Render(testReport, FormatJson)
+	if err != nil {
+		t.Fatalf("Render() unexpected error: %v", err)
+	}
+
+	var parsed struct {
+		Changed bool         `json:"changed"`
+		Hosts   []HostReport `json:"hosts"`
+	}
+	if err := json.Unmarshal([]byte(got), &parsed); err != nil {
+		t.Fatalf("Render() produced invalid JSON: %v", err)
+	}
+
+	if !parsed.Changed || len(parsed.Hosts) != len(testReport.Hosts) {
+		t.Errorf("Render() = %+v, want %+v", parsed, testReport)
+	}
+}
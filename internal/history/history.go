@@ -0,0 +1,280 @@
+package history
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"port-scanner/internal/types"
+	"strconv"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+const (
+	LatestID            = "latest"
+	directoryPermission = 0755
+	filePermission      = 0600
+	lockTimeout         = 5 * time.Second
+)
+
+var (
+	scansBucket   = []byte("scans")
+	resultsBucket = []byte("results")
+)
+
+var (
+	openHistoryError  = errors.New("failed to open history")
+	writeHistoryError = errors.New("failed to write history")
+	readHistoryError  = errors.New("failed to read history")
+	scanNotFoundError = errors.New("scan not found in history")
+	invalidIDError    = errors.New("invalid scan id: expected a number or latest")
+)
+
+type Scan struct {
+	ID         uint64            `json:"id"`
+	Metadata   types.Metadata    `json:"metadata"`
+	Summary    types.Summary     `json:"summary"`
+	Violations []types.Violation `json:"violations,omitempty"`
+}
+
+type Store struct {
+	db *bolt.DB
+}
+
+func Open(path string, readOnly bool) (*Store, error) {
+	if !readOnly {
+		err := os.MkdirAll(filepath.Dir(path), directoryPermission)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", openHistoryError, path, err)
+		}
+	} else if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", openHistoryError, path, err)
+	}
+
+	db, err := bolt.Open(path, filePermission, &bolt.Options{Timeout: lockTimeout, ReadOnly: readOnly})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", openHistoryError, path, err)
+	}
+
+	if !readOnly {
+		err = db.Update(func(tx *bolt.Tx) error {
+			for _, bucket := range [][]byte{scansBucket, resultsBucket} {
+				if _, err := tx.CreateBucketIfNotExists(bucket); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			_ = db.Close()
+			return nil, fmt.Errorf("%w: %s: %v", openHistoryError, path, err)
+		}
+	}
+
+	return &Store{db: db}, nil
+}
+
+func Record(path string, report types.Report) (uint64, error) {
+	store, err := Open(path, false)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_ = store.Close()
+	}()
+
+	return store.Record(report)
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+func (s *Store) Record(report types.Report) (uint64, error) {
+	var id uint64
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		scans := tx.Bucket(scansBucket)
+		seq, err := scans.NextSequence()
+		if err != nil {
+			return err
+		}
+		id = seq
+
+		scan, err := json.Marshal(Scan{ID: id, Metadata: report.Metadata, Summary: report.Summary, Violations: report.Violations})
+		if err != nil {
+			return err
+		}
+
+		results, err := json.Marshal(recordedResults(report.Results))
+		if err != nil {
+			return err
+		}
+
+		err = scans.Put(key(id), scan)
+		if err != nil {
+			return err
+		}
+		return tx.Bucket(resultsBucket).Put(key(id), results)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", writeHistoryError, err)
+	}
+	return id, nil
+}
+
+func recordedResults(results []types.Result) []types.Result {
+	recorded := make([]types.Result, 0)
+	for _, r := range results {
+		if r.Status || r.Skipped {
+			recorded = append(recorded, r)
+		}
+	}
+	return recorded
+}
+
+func (s *Store) List() ([]Scan, error) {
+	scans := make([]Scan, 0)
+	err := s.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(scansBucket)
+		if bucket == nil {
+			return nil
+		}
+
+		return bucket.ForEach(func(_, value []byte) error {
+			var scan Scan
+			err := json.Unmarshal(value, &scan)
+			if err != nil {
+				return err
+			}
+			scans = append(scans, scan)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", readHistoryError, err)
+	}
+	return scans, nil
+}
+
+func (s *Store) Find(host string, port int) ([]Scan, error) {
+	scans, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+
+	found := make([]Scan, 0)
+	for _, scan := range scans {
+		results, err := s.results(scan.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, r := range results {
+			if r.Status && r.Port == port && (host == "" || r.Host == host) {
+				found = append(found, scan)
+				break
+			}
+		}
+	}
+	return found, nil
+}
+
+func (s *Store) Report(id string) (Scan, types.Report, error) {
+	scanID, err := s.resolve(id)
+	if err != nil {
+		return Scan{}, types.Report{}, err
+	}
+
+	var scan Scan
+	err = s.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(scansBucket)
+		if bucket == nil {
+			return scanNotFoundError
+		}
+
+		value := bucket.Get(key(scanID))
+		if value == nil {
+			return scanNotFoundError
+		}
+		return json.Unmarshal(value, &scan)
+	})
+	if errors.Is(err, scanNotFoundError) {
+		return Scan{}, types.Report{}, fmt.Errorf("%w: %s", scanNotFoundError, id)
+	}
+	if err != nil {
+		return Scan{}, types.Report{}, fmt.Errorf("%w: %v", readHistoryError, err)
+	}
+
+	results, err := s.results(scanID)
+	if err != nil {
+		return Scan{}, types.Report{}, err
+	}
+
+	return scan, types.Report{
+		Results:    results,
+		Summary:    scan.Summary,
+		Metadata:   scan.Metadata,
+		Violations: scan.Violations,
+	}, nil
+}
+
+func (s *Store) results(id uint64) ([]types.Result, error) {
+	results := make([]types.Result, 0)
+	err := s.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(resultsBucket)
+		if bucket == nil {
+			return nil
+		}
+
+		value := bucket.Get(key(id))
+		if value == nil {
+			return nil
+		}
+		return json.Unmarshal(value, &results)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", readHistoryError, err)
+	}
+	return results, nil
+}
+
+func (s *Store) resolve(id string) (uint64, error) {
+	if id != LatestID {
+		n, err := strconv.ParseUint(id, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("%w: %q", invalidIDError, id)
+		}
+		return n, nil
+	}
+
+	var latest uint64
+	err := s.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(scansBucket)
+		if bucket == nil {
+			return nil
+		}
+
+		k, _ := bucket.Cursor().Last()
+		if k != nil {
+			latest = binary.BigEndian.Uint64(k)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", readHistoryError, err)
+	}
+	if latest == 0 {
+		return 0, fmt.Errorf("%w: %s", scanNotFoundError, id)
+	}
+	return latest, nil
+}
+
+func key(id uint64) []byte {
+	k := make([]byte, 8)
+	binary.BigEndian.PutUint64(k, id)
+	return k
+}
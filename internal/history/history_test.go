@@ -0,0 +1,198 @@
+package history
+
+import (
+	"errors"
+	"path/filepath"
+	"port-scanner/internal/types"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func report(start time.Time, results ...types.Result) types.Report {
+	open := 0
+	for _, r := range results {
+		if r.Status {
+			open++
+		}
+	}
+
+	return types.Report{
+		Results:  results,
+		Summary:  types.Summary{Hosts: 1, Ports: len(results), Scanned: len(results), Open: open},
+		Metadata: types.Metadata{Targets: []string{"10.0.0.1"}, Ports: "22,80,8080", Start: start, End: start.Add(time.Second)},
+	}
+}
+
+func record(t *testing.T, path string, reports ...types.Report) {
+	t.Helper()
+	for i, r := range reports {
+		id, err := Record(path, r)
+		if err != nil {
+			t.Fatalf("Record() unexpected error: %v", err)
+		}
+		if id != uint64(i+1) {
+			t.Fatalf("Record() id = %d, want %d", id, i+1)
+		}
+	}
+}
+
+func TestRecordAndReport(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "history.db")
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	first := report(start, types.Result{Host: "10.0.0.1", Port: 22, Status: true, Service: "ssh"})
+	second := report(start.Add(time.Hour),
+		types.Result{Host: "10.0.0.1", Port: 22, Status: true, Service: "ssh"},
+		types.Result{Host: "10.0.0.1", Port: 8080, Status: true},
+	)
+	second.Violations = []types.Violation{{Host: "10.0.0.1", Port: 8080, Rule: "port-not-allowed"}}
+	record(t, path, first, second)
+
+	store, err := Open(path, true)
+	if err != nil {
+		t.Fatalf("Open() unexpected error: %v", err)
+	}
+	defer func() {
+		_ = store.Close()
+	}()
+
+	tests := []struct {
+		id       string
+		expected types.Report
+		wantErr  error
+	}{
+		{"1", first, nil},
+		{"2", second, nil},
+		{LatestID, second, nil},
+		{"3", types.Report{}, scanNotFoundError},
+		{"abc", types.Report{}, invalidIDError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			_, got, err := store.Report(tt.id)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Report() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			if !got.Metadata.Start.Equal(tt.expected.Metadata.Start) {
+				t.Errorf("Report() start = %v, want %v", got.Metadata.Start, tt.expected.Metadata.Start)
+			}
+			got.Metadata.Start, got.Metadata.End = tt.expected.Metadata.Start, tt.expected.Metadata.End
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Report() = %+v, want %+v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRecordKeepsOpenAndSkipped(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.db")
+	scan := report(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+		types.Result{Host: "10.0.0.1", Port: 22, Status: true, Service: "ssh"},
+		types.Result{Host: "10.0.0.1", Port: 80, Status: false},
+		types.Result{Host: "10.0.0.1", Port: 8080, Skipped: true},
+	)
+	record(t, path, scan)
+
+	store, err := Open(path, true)
+	if err != nil {
+		t.Fatalf("Open() unexpected error: %v", err)
+	}
+	defer func() {
+		_ = store.Close()
+	}()
+
+	_, got, err := store.Report(LatestID)
+	if err != nil {
+		t.Fatalf("Report() unexpected error: %v", err)
+	}
+
+	expected := []types.Result{scan.Results[0], scan.Results[2]}
+	if !reflect.DeepEqual(got.Results, expected) {
+		t.Errorf("Report() results = %+v, want %+v", got.Results, expected)
+	}
+	if got.Summary != scan.Summary {
+		t.Errorf("Report() summary = %+v, want %+v", got.Summary, scan.Summary)
+	}
+}
+
+func TestListAndFind(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.db")
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	record(t, path,
+		report(start, types.Result{Host: "10.0.0.1", Port: 8080, Status: false}),
+		report(start.Add(time.Hour), types.Result{Host: "10.0.0.1", Port: 8080, Status: true}),
+		report(start.Add(2*time.Hour), types.Result{Host: "10.0.0.2", Port: 8080, Status: true}),
+	)
+
+	store, err := Open(path, true)
+	if err != nil {
+		t.Fatalf("Open() unexpected error: %v", err)
+	}
+	defer func() {
+		_ = store.Close()
+	}()
+
+	scans, err := store.List()
+	if err != nil {
+		t.Fatalf("List() unexpected error: %v", err)
+	}
+	if len(scans) != 3 || scans[0].ID != 1 || scans[2].ID != 3 {
+		t.Errorf("List() = %+v, want scans 1 to 3 in order", scans)
+	}
+
+	tests := []struct {
+		name     string
+		host     string
+		port     int
+		expected []uint64
+	}{
+		{"first appearance on host", "10.0.0.1", 8080, []uint64{2}},
+		{"any host", "", 8080, []uint64{2, 3}},
+		{"never open", "10.0.0.1", 22, []uint64{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found, err := store.Find(tt.host, tt.port)
+			if err != nil {
+				t.Fatalf("Find() unexpected error: %v", err)
+			}
+			ids := make([]uint64, 0, len(found))
+			for _, s := range found {
+				ids = append(ids, s.ID)
+			}
+			if !reflect.DeepEqual(ids, tt.expected) {
+				t.Errorf("Find() = %v, want %v", ids, tt.expected)
+			}
+		})
+	}
+}
+
+func TestOpenMissing(t *testing.T) {
+	_, err := Open(filepath.Join(t.TempDir(), "missing.db"), true)
+	if !errors.Is(err, openHistoryError) {
+		t.Errorf("Open() error = %v, want %v", err, openHistoryError)
+	}
+}
+
+func TestLatestEmpty(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.db")
+	store, err := Open(path, false)
+	if err != nil {
+		t.Fatalf("Open() unexpected error: %v", err)
+	}
+	defer func() {
+		_ = store.Close()
+	}()
+
+	_, _, err = store.Report(LatestID)
+	if !errors.Is(err, scanNotFoundError) {
+		t.Errorf("Report() error = %v, want %v", err, scanNotFoundError)
+	}
+}
@@ -0,0 +1,59 @@
+package hostmatch
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"strings"
+)
+
+const (
+	Unknown       = "-"
+	cidrSeparator = "/"
+)
+
+var (
+	invalidCIDRError = errors.New("invalid cidr")
+)
+
+func Name(host string) string {
+	if host == "" {
+		return Unknown
+	}
+	return host
+}
+
+func Lookup[T any](hosts map[string]T, fallback T, host string) T {
+	if value, ok := hosts[host]; ok {
+		return value
+	}
+
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return fallback
+	}
+
+	bits := -1
+	value := fallback
+	for key, candidate := range hosts {
+		prefix, err := netip.ParsePrefix(key)
+		if err != nil || !prefix.Contains(addr) || prefix.Bits() <= bits {
+			continue
+		}
+		bits = prefix.Bits()
+		value = candidate
+	}
+	return value
+}
+
+func Validate[T any](hosts map[string]T) error {
+	for key := range hosts {
+		if !strings.Contains(key, cidrSeparator) {
+			continue
+		}
+		if _, err := netip.ParsePrefix(key); err != nil {
+			return fmt.Errorf("%w %q", invalidCIDRError, key)
+		}
+	}
+	return nil
+}
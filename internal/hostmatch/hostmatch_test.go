@@ -0,0 +1,81 @@
+package hostmatch
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestName(t *testing.T) {
+	tests := []struct {
+		host     string
+		expected string
+	}{
+		{"", Unknown},
+		{"10.0.0.1", "10.0.0.1"},
+		{"example.com", "example.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			if got := Name(tt.host); got != tt.expected {
+				t.Errorf("Name(%q) = %q, want %q", tt.host, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestLookup(t *testing.T) {
+	hosts := map[string]string{
+		"10.0.0.0/8":    "wide",
+		"10.0.0.0/24":   "narrow",
+		"10.0.0.5":      "exact",
+		"example.com":   "name",
+		"2001:db8::/32": "v6",
+	}
+
+	tests := []struct {
+		host     string
+		expected string
+	}{
+		{"10.0.0.5", "exact"},
+		{"10.0.0.6", "narrow"},
+		{"10.1.0.1", "wide"},
+		{"192.168.0.1", "default"},
+		{"example.com", "name"},
+		{"other.example.com", "default"},
+		{"2001:db8::1", "v6"},
+		{"", "default"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			if got := Lookup(hosts, "default", tt.host); got != tt.expected {
+				t.Errorf("Lookup(%q) = %q, want %q", tt.host, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		hosts   map[string]int
+		wantErr bool
+	}{
+		{"hosts and cidrs", map[string]int{"10.0.0.1": 1, "10.0.0.0/24": 2, "example.com": 3}, false},
+		{"invalid cidr", map[string]int{"10.0.0.0/33": 1}, true},
+		{"empty", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.hosts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, invalidCIDRError) {
+				t.Errorf("Validate() error = %v, want %v", err, invalidCIDRError)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package merge
+
+import (
+	"port-scanner/internal/types"
+	"sort"
+	"time"
+)
+
+type Input struct {
+	Name    string
+	Results []types.Result
+	Start   time.Time
+	End     time.Time
+}
+
+type Duplicate struct {
+	Host     string   `json:"host"`
+	Port     int      `json:"port"`
+	Sources  []string `json:"sources"`
+	Conflict bool     `json:"conflict"`
+}
+
+type Merged struct {
+	Results    []types.Result
+	Duplicates []Duplicate
+	Start      time.Time
+	End        time.Time
+}
+
+type endpoint struct {
+	host string
+	port int
+}
+
+type entry struct {
+	result  types.Result
+	sources []string
+	dup     bool
+	clash   bool
+}
+
+func Merge(inputs []Input) Merged {
+	hostOrder := make(map[string]int)
+	entries := make(map[endpoint]*entry)
+	keys := make([]endpoint, 0)
+	var start, end time.Time
+
+	for _, input := range inputs {
+		if !input.Start.IsZero() && (start.IsZero() || input.Start.Before(start)) {
+			start = input.Start
+		}
+		if input.End.After(end) {
+			end = input.End
+		}
+
+		for _, r := range input.Results {
+			if _, ok := hostOrder[r.Host]; !ok {
+				hostOrder[r.Host] = len(hostOrder)
+			}
+
+			key := endpoint{host: r.Host, port: r.Port}
+			e, ok := entries[key]
+			if !ok {
+				entries[key] = &entry{result: r, sources: []string{input.Name}}
+				keys = append(keys, key)
+				continue
+			}
+
+			e.dup = true
+			e.sources = append(e.sources, input.Name)
+			if conflicts(e.result, r) {
+				e.clash = true
+			}
+			e.result = combine(e.result, r)
+		}
+	}
+
+	sort.SliceStable(keys, func(i, j int) bool {
+		if keys[i].host != keys[j].host {
+			return hostOrder[keys[i].host] < hostOrder[keys[j].host]
+		}
+		return keys[i].port < keys[j].port
+	})
+
+	merged := Merged{Results: make([]types.Result, 0, len(keys)), Duplicates: make([]Duplicate, 0), Start: start, End: end}
+	for _, key := range keys {
+		e := entries[key]
+		merged.Results = append(merged.Results, e.result)
+		if e.dup {
+			merged.Duplicates = append(merged.Duplicates, Duplicate{Host: key.host, Port: key.port, Sources: e.sources, Conflict: e.clash})
+		}
+	}
+	return merged
+}
+
+func (m Merged) Summary() types.Summary {
+	hosts := make(map[string]bool)
+	ports := make(map[int]bool)
+	summary := types.Summary{Scanned: len(m.Results)}
+
+	for _, r := range m.Results {
+		hosts[r.Host] = true
+		ports[r.Port] = true
+		if r.Status {
+			summary.Open++
+		}
+	}
+
+	summary.Hosts = len(hosts)
+	summary.Ports = len(ports)
+	return summary
+}
+
+func (m Merged) Conflicts() int {
+	count := 0
+	for _, d := range m.Duplicates {
+		if d.Conflict {
+			count++
+		}
+	}
+	return count
+}
+
+func conflicts(a, b types.Result) bool {
+	return a.Status != b.Status || (a.Banner != "" && b.Banner != "" && a.Banner != b.Banner)
+}
+
+func combine(kept, other types.Result) types.Result {
+	if other.Status && !kept.Status {
+		kept, other = other, kept
+	}
+
+	if kept.Service == "" {
+		kept.Service = other.Service
+	}
+	if kept.Banner == "" && other.Status {
+		kept.Banner = other.Banner
+	}
+	if kept.Latency == 0 && other.Status {
+		kept.Latency = other.Latency
+	}
+	return kept
+}
@@ -0,0 +1,129 @@
+package merge
+
+import (
+	"port-scanner/internal/types"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMerge(t *testing.T) {
+	tests := []struct {
+		name       string
+		inputs     []Input
+		expected   []types.Result
+		duplicates []Duplicate
+	}{
+		{
+			name: "disjoint shards",
+			inputs: []Input{
+				{Name: "shard-1.json", Results: []types.Result{{Host: "10.0.0.2", Port: 22}, {Host: "10.0.0.1", Port: 80, Status: true}}},
+				{Name: "shard-2.json", Results: []types.Result{{Host: "10.0.0.1", Port: 22, Status: true}, {Host: "10.0.0.2", Port: 80}}},
+			},
+			expected: []types.Result{
+				{Host: "10.0.0.2", Port: 22},
+				{Host: "10.0.0.2", Port: 80},
+				{Host: "10.0.0.1", Port: 22, Status: true},
+				{Host: "10.0.0.1", Port: 80, Status: true},
+			},
+			duplicates: []Duplicate{},
+		},
+		{
+			name: "identical duplicate",
+			inputs: []Input{
+				{Name: "a.csv", Results: []types.Result{{Host: "10.0.0.1", Port: 22, Status: true, Service: "ssh"}}},
+				{Name: "b.csv", Results: []types.Result{{Host: "10.0.0.1", Port: 22, Status: true, Service: "ssh"}}},
+			},
+			expected:   []types.Result{{Host: "10.0.0.1", Port: 22, Status: true, Service: "ssh"}},
+			duplicates: []Duplicate{{Host: "10.0.0.1", Port: 22, Sources: []string{"a.csv", "b.csv"}}},
+		},
+		{
+			name: "conflicting duplicate keeps the open result",
+			inputs: []Input{
+				{Name: "a.json", Results: []types.Result{{Host: "10.0.0.1", Port: 8080}}},
+				{Name: "b.json", Results: []types.Result{{Host: "10.0.0.1", Port: 8080, Status: true, Banner: "nginx"}}},
+				{Name: "c.json", Results: []types.Result{{Host: "10.0.0.1", Port: 8080, Status: true, Latency: 1.5}}},
+			},
+			expected: []types.Result{{Host: "10.0.0.1", Port: 8080, Status: true, Banner: "nginx", Latency: 1.5}},
+			duplicates: []Duplicate{
+				{Host: "10.0.0.1", Port: 8080, Sources: []string{"a.json", "b.json", "c.json"}, Conflict: true},
+			},
+		},
+		{
+			name:       "no inputs",
+			inputs:     nil,
+			expected:   []types.Result{},
+			duplicates: []Duplicate{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Merge(tt.inputs)
+			if !reflect.DeepEqual(got.Results, tt.expected) {
+				t.Errorf("Merge() results = %+v, want %+v", got.Results, tt.expected)
+			}
+			if !reflect.DeepEqual(got.Duplicates, tt.duplicates) {
+				t.Errorf("Merge() duplicates = %+v, want %+v", got.Duplicates, tt.duplicates)
+			}
+		})
+	}
+}
+
+func TestSummary(t *testing.T) {
+	merged := Merge([]Input{
+		{Name: "a", Results: []types.Result{{Host: "10.0.0.1", Port: 22, Status: true}, {Host: "10.0.0.1", Port: 80}}},
+		{Name: "b", Results: []types.Result{{Host: "10.0.0.2", Port: 22}, {Host: "10.0.0.1", Port: 80, Status: true}}},
+	})
+
+	expected := types.Summary{Hosts: 2, Ports: 2, Scanned: 3, Open: 2}
+	if got := merged.Summary(); got != expected {
+		t.Errorf("Summary() = %+v, want %+v", got, expected)
+	}
+	if got := merged.Conflicts(); got != 1 {
+		t.Errorf("Conflicts() = %d, want 1", got)
+	}
+}
+
+func TestMergeWindow(t *testing.T) {
+	start := time.Date(2026, 1, 2, 3, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		inputs []Input
+		start  time.Time
+		end    time.Time
+	}{
+		{
+			name: "earliest start and latest end",
+			inputs: []Input{
+				{Name: "a", Start: start.Add(time.Minute), End: start.Add(5 * time.Minute)},
+				{Name: "b", Start: start, End: start.Add(3 * time.Minute)},
+				{Name: "c", Start: start.Add(2 * time.Minute), End: start.Add(9 * time.Minute)},
+			},
+			start: start,
+			end:   start.Add(9 * time.Minute),
+		},
+		{
+			name: "inputs without a window are ignored",
+			inputs: []Input{
+				{Name: "a.csv"},
+				{Name: "b.json", Start: start, End: start.Add(time.Minute)},
+			},
+			start: start,
+			end:   start.Add(time.Minute),
+		},
+		{
+			name:   "no window",
+			inputs: []Input{{Name: "a.csv"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Merge(tt.inputs)
+			if !got.Start.Equal(tt.start) || !got.End.Equal(tt.end) {
+				t.Errorf("Merge() window = %v - %v, want %v - %v", got.Start, got.End, tt.start, tt.end)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"port-scanner/internal/output"
+	"port-scanner/internal/types"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	metricsPath      = "/metrics"
+	contentType      = "text/plain; version=0.0.4; charset=utf-8"
+	metricScans      = "port_scanner_scans_total"
+	metricScanErrors = "port_scanner_scan_errors_total"
+	shutdownTimeout  = 5 * time.Second
+)
+
+type Server struct {
+	mu     sync.RWMutex
+	report *types.Report
+	scans  int
+	errors int
+}
+
+func NewServer() *Server {
+	return &Server{}
+}
+
+func (s *Server) Observe(report types.Report, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.scans++
+	if err != nil {
+		s.errors++
+		return
+	}
+	s.report = &report
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != metricsPath {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	_, _ = w.Write([]byte(s.render()))
+}
+
+func (s *Server) render() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var sb strings.Builder
+	if s.report != nil {
+		metrics, err := output.Render(*s.report, output.FormatProm, types.Config{})
+		if err == nil {
+			sb.WriteString(metrics)
+		}
+	}
+
+	output.WriteMetric(&sb, metricScans, output.MetricCounter, "Scans run since start.", float64(s.scans))
+	output.WriteMetric(&sb, metricScanErrors, output.MetricCounter, "Scans that failed since start.", float64(s.errors))
+	return sb.String()
+}
+
+func (s *Server) Serve(ctx context.Context, listener net.Listener) error {
+	server := &http.Server{Handler: s, ReadHeaderTimeout: shutdownTimeout}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+
+	err := server.Serve(listener)
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,113 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"port-scanner/internal/types"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestServer(t *testing.T) {
+	report := types.Report{Results: []types.Result{{Host: "10.0.0.1", Port: 22, Status: true, Service: "ssh"}}}
+
+	tests := []struct {
+		name        string
+		observe     []error
+		status      int
+		path        string
+		contains    []string
+		notContains []string
+	}{
+		{
+			name:        "before the first scan",
+			path:        "/metrics",
+			status:      http.StatusOK,
+			contains:    []string{"port_scanner_scans_total 0\n", "port_scanner_scan_errors_total 0\n"},
+			notContains: []string{"port_scanner_port_open"},
+		},
+		{
+			name:    "after scans",
+			observe: []error{nil, errors.New("scan failed")},
+			path:    "/metrics",
+			status:  http.StatusOK,
+			contains: []string{
+				`port_scanner_port_open{host="10.0.0.1",port="22",protocol="tcp",service="ssh"} 1`,
+				"# TYPE port_scanner_scans_total counter\n",
+				"port_scanner_scans_total 2\n",
+				"port_scanner_scan_errors_total 1\n",
+			},
+		},
+		{
+			name:   "unknown path",
+			path:   "/",
+			status: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := NewServer()
+			for _, err := range tt.observe {
+				server.Observe(report, err)
+			}
+
+			recorder := httptest.NewRecorder()
+			server.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if recorder.Code != tt.status {
+				t.Fatalf("status = %d, want %d", recorder.Code, tt.status)
+			}
+			body := recorder.Body.String()
+			for _, want := range tt.contains {
+				if !strings.Contains(body, want) {
+					t.Errorf("body missing %q in:\n%s", want, body)
+				}
+			}
+			for _, unwanted := range tt.notContains {
+				if strings.Contains(body, unwanted) {
+					t.Errorf("body contains %q in:\n%s", unwanted, body)
+				}
+			}
+		})
+	}
+}
+
+func TestServe(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to create test listener: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- NewServer().Serve(ctx, listener)
+	}()
+
+	resp, err := http.Get("http://" + listener.Addr().String() + "/metrics")
+	if err != nil {
+		t.Fatalf("GET /metrics error = %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+
+	if resp.Header.Get("Content-Type") != contentType || !strings.Contains(string(body), "port_scanner_scans_total 0") {
+		t.Errorf("GET /metrics = %q (%s)", body, resp.Header.Get("Content-Type"))
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Serve() error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Serve() did not return after cancel")
+	}
+}
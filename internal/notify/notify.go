@@ -0,0 +1,261 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"port-scanner/internal/diff"
+	"port-scanner/internal/types"
+	"strings"
+	"time"
+)
+
+type Kind string
+
+const (
+	KindJSON  Kind = "json"
+	KindSlack Kind = "slack"
+	KindTeams Kind = "teams"
+)
+
+const (
+	EventCompleted   = "scan.completed"
+	EventPortsOpened = "ports.opened"
+	toolName         = "port-scanner"
+	headerSignature  = "X-Port-Scanner-Signature"
+	headerEvent      = "X-Port-Scanner-Event"
+	signaturePrefix  = "sha256="
+	contentTypeJSON  = "application/json"
+	defaultBackoff   = 500 * time.Millisecond
+	maxBackoff       = 30 * time.Second
+)
+
+var (
+	invalidWebhookError = errors.New("invalid webhook: expected [json|slack|teams:]https://host/path")
+	webhookFailedError  = errors.New("webhook failed")
+)
+
+type Webhook struct {
+	Kind Kind
+	URL  string
+}
+
+type Options struct {
+	Webhooks []Webhook
+	Secret   string
+	Timeout  time.Duration
+	Retries  int
+	Version  string
+}
+
+type Notifier struct {
+	opts    Options
+	client  *http.Client
+	backoff time.Duration
+}
+
+type Event struct {
+	Event      string            `json:"event"`
+	Time       time.Time         `json:"time"`
+	Tool       Tool              `json:"tool"`
+	Targets    []string          `json:"targets,omitempty"`
+	Summary    *types.Summary    `json:"summary,omitempty"`
+	Open       []types.Result    `json:"open"`
+	Changes    []diff.Change     `json:"changes,omitempty"`
+	Violations []types.Violation `json:"violations,omitempty"`
+}
+
+type Tool struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+func ParseWebhook(s string) (Webhook, error) {
+	webhook := Webhook{Kind: KindJSON, URL: s}
+	for _, kind := range []Kind{KindJSON, KindSlack, KindTeams} {
+		if rest, ok := strings.CutPrefix(s, string(kind)+":"); ok {
+			webhook = Webhook{Kind: kind, URL: rest}
+			break
+		}
+	}
+
+	u, err := url.Parse(webhook.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return Webhook{}, fmt.Errorf("%w: %q", invalidWebhookError, s)
+	}
+	return webhook, nil
+}
+
+func New(cfg types.Config, version string) (*Notifier, error) {
+	opts := Options{
+		Secret:  cfg.WebhookSecret,
+		Timeout: time.Duration(cfg.WebhookTimeout) * time.Millisecond,
+		Retries: cfg.WebhookRetries,
+		Version: version,
+	}
+
+	for _, s := range cfg.Webhooks {
+		webhook, err := ParseWebhook(s)
+		if err != nil {
+			return nil, err
+		}
+		opts.Webhooks = append(opts.Webhooks, webhook)
+	}
+
+	return NewNotifier(opts), nil
+}
+
+func NewNotifier(opts Options) *Notifier {
+	return &Notifier{
+		opts:    opts,
+		client:  &http.Client{Timeout: opts.Timeout},
+		backoff: defaultBackoff,
+	}
+}
+
+func (n *Notifier) Completed(ctx context.Context, report types.Report) error {
+	summary := report.Summary
+	return n.Send(ctx, Event{
+		Event:      EventCompleted,
+		Time:       time.Now().UTC(),
+		Targets:    report.Metadata.Targets,
+		Summary:    &summary,
+		Open:       openResults(report.Results),
+		Violations: report.Violations,
+	})
+}
+
+func (n *Notifier) Opened(ctx context.Context, results []types.Result, changes []diff.Change) error {
+	opened := make([]diff.Change, 0, len(changes))
+	for _, c := range changes {
+		if c.Kind == diff.KindOpened {
+			opened = append(opened, c)
+		}
+	}
+	if len(opened) == 0 {
+		return nil
+	}
+
+	return n.Send(ctx, Event{
+		Event:   EventPortsOpened,
+		Time:    time.Now().UTC(),
+		Open:    openResults(results),
+		Changes: opened,
+	})
+}
+
+func (n *Notifier) Send(ctx context.Context, event Event) error {
+	if n == nil || len(n.opts.Webhooks) == 0 {
+		return nil
+	}
+
+	event.Tool = Tool{Name: toolName, Version: n.opts.Version}
+	if event.Open == nil {
+		event.Open = []types.Result{}
+	}
+
+	errs := make([]error, 0)
+	for _, webhook := range n.opts.Webhooks {
+		body, err := json.Marshal(payload(webhook.Kind, event))
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		err = n.post(ctx, webhook, event.Event, body)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (n *Notifier) post(ctx context.Context, webhook Webhook, event string, body []byte) error {
+	var err error
+	for attempt := 0; ; attempt++ {
+		var retry bool
+		retry, err = n.attempt(ctx, webhook, event, body)
+		if err == nil {
+			return nil
+		}
+		if !retry || attempt >= n.opts.Retries || !wait(ctx, n.delay(attempt)) {
+			return fmt.Errorf("%w: %s: %v", webhookFailedError, redact(webhook.URL), err)
+		}
+	}
+}
+
+func (n *Notifier) attempt(ctx context.Context, webhook Webhook, event string, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+
+	req.Header.Set("Content-Type", contentTypeJSON)
+	req.Header.Set(headerEvent, event)
+	if n.opts.Secret != "" {
+		req.Header.Set(headerSignature, Sign(n.opts.Secret, body))
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, err
+	}
+	_ = resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, fmt.Errorf("unexpected status %s", resp.Status)
+}
+
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+func openResults(results []types.Result) []types.Result {
+	open := make([]types.Result, 0)
+	for _, r := range results {
+		if r.Status {
+			open = append(open, r)
+		}
+	}
+	return open
+}
+
+func redact(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return raw
+	}
+	return u.Scheme + "://" + u.Host
+}
+
+func (n *Notifier) delay(attempt int) time.Duration {
+	d := n.backoff
+	for i := 0; i < attempt && d < maxBackoff; i++ {
+		d *= 2
+	}
+	return min(d, maxBackoff)
+}
+
+func wait(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -0,0 +1,248 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"port-scanner/internal/diff"
+	"port-scanner/internal/types"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type recorder struct {
+	mu       sync.Mutex
+	requests []*http.Request
+	bodies   [][]byte
+	statuses []int
+}
+
+func (r *recorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests = append(r.requests, req)
+	r.bodies = append(r.bodies, body)
+
+	status := http.StatusOK
+	if len(r.statuses) > 0 {
+		status, r.statuses = r.statuses[0], r.statuses[1:]
+	}
+	w.WriteHeader(status)
+}
+
+func TestParseWebhook(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Webhook
+		wantErr  bool
+	}{
+		{"https://example.com/hook", Webhook{Kind: KindJSON, URL: "https://example.com/hook"}, false},
+		{"json:http://10.0.0.1:8080/hook", Webhook{Kind: KindJSON, URL: "http://10.0.0.1:8080/hook"}, false},
+		{"slack:https://hooks.slack.com/services/T/B/X", Webhook{Kind: KindSlack, URL: "https://hooks.slack.com/services/T/B/X"}, false},
+		{"teams:https://example.webhook.office.com/x", Webhook{Kind: KindTeams, URL: "https://example.webhook.office.com/x"}, false},
+		{"discord:https://example.com", Webhook{}, true},
+		{"ftp://example.com", Webhook{}, true},
+		{"https://", Webhook{}, true},
+		{"", Webhook{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseWebhook(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseWebhook() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.expected {
+				t.Errorf("ParseWebhook() = %+v, want %+v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCompleted(t *testing.T) {
+	rec := &recorder{}
+	srv := httptest.NewServer(rec)
+	defer srv.Close()
+
+	n := NewNotifier(Options{
+		Webhooks: []Webhook{{Kind: KindJSON, URL: srv.URL}},
+		Secret:   "secret",
+		Timeout:  time.Second,
+		Version:  "1.0.0",
+	})
+
+	report := types.Report{
+		Results: []types.Result{
+			{Host: "10.0.0.1", Port: 22, Status: true, Service: "ssh"},
+			{Host: "10.0.0.1", Port: 23, Status: false},
+		},
+		Summary:  types.Summary{Hosts: 1, Ports: 2, Scanned: 2, Open: 1},
+		Metadata: types.Metadata{Targets: []string{"10.0.0.1"}},
+	}
+
+	err := n.Completed(context.Background(), report)
+	if err != nil {
+		t.Fatalf("Completed() unexpected error: %v", err)
+	}
+
+	if len(rec.requests) != 1 {
+		t.Fatalf("requests = %d, want 1", len(rec.requests))
+	}
+
+	req, body := rec.requests[0], rec.bodies[0]
+	if got := req.Header.Get(headerSignature); got != Sign("secret", body) {
+		t.Errorf("signature = %q, want %q", got, Sign("secret", body))
+	}
+	if got := req.Header.Get(headerEvent); got != EventCompleted {
+		t.Errorf("event header = %q, want %q", got, EventCompleted)
+	}
+
+	var event Event
+	if err := json.Unmarshal(body, &event); err != nil {
+		t.Fatalf("invalid body %q: %v", body, err)
+	}
+	if event.Event != EventCompleted || event.Tool.Version != "1.0.0" || event.Summary.Open != 1 {
+		t.Errorf("event = %+v, want completed event with summary", event)
+	}
+	if !reflect.DeepEqual(event.Open, report.Results[:1]) {
+		t.Errorf("open = %+v, want %+v", event.Open, report.Results[:1])
+	}
+}
+
+func TestOpened(t *testing.T) {
+	rec := &recorder{}
+	srv := httptest.NewServer(rec)
+	defer srv.Close()
+
+	n := NewNotifier(Options{Webhooks: []Webhook{{Kind: KindJSON, URL: srv.URL}}, Timeout: time.Second})
+
+	err := n.Opened(context.Background(), nil, []diff.Change{{Kind: diff.KindClosed, Host: "10.0.0.1", Port: 22}})
+	if err != nil || len(rec.requests) != 0 {
+		t.Fatalf("Opened() without opened ports = %v, %d requests, want nothing sent", err, len(rec.requests))
+	}
+
+	changes := []diff.Change{
+		{Kind: diff.KindClosed, Host: "10.0.0.1", Port: 22},
+		{Kind: diff.KindOpened, Host: "10.0.0.1", Port: 8080},
+	}
+	err = n.Opened(context.Background(), []types.Result{{Host: "10.0.0.1", Port: 8080, Status: true}}, changes)
+	if err != nil {
+		t.Fatalf("Opened() unexpected error: %v", err)
+	}
+
+	var event Event
+	if err := json.Unmarshal(rec.bodies[0], &event); err != nil {
+		t.Fatalf("invalid body: %v", err)
+	}
+	if event.Event != EventPortsOpened || !reflect.DeepEqual(event.Changes, changes[1:]) {
+		t.Errorf("event = %+v, want only the opened change", event)
+	}
+	if rec.requests[0].Header.Get(headerSignature) != "" {
+		t.Errorf("signature set without a secret")
+	}
+}
+
+func TestRetries(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []int
+		retries  int
+		requests int
+		wantErr  bool
+	}{
+		{"success", []int{http.StatusNoContent}, 3, 1, false},
+		{"retry server errors", []int{http.StatusBadGateway, http.StatusTooManyRequests, http.StatusOK}, 3, 3, false},
+		{"retries exhausted", []int{500, 500, 500}, 2, 3, true},
+		{"client error is not retried", []int{http.StatusNotFound}, 3, 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := &recorder{statuses: tt.statuses}
+			srv := httptest.NewServer(rec)
+			defer srv.Close()
+
+			n := NewNotifier(Options{Webhooks: []Webhook{{Kind: KindJSON, URL: srv.URL + "/secret-path"}}, Timeout: time.Second, Retries: tt.retries})
+			n.backoff = time.Millisecond
+
+			err := n.Send(context.Background(), Event{Event: EventCompleted})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Send() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && (!errors.Is(err, webhookFailedError) || strings.Contains(err.Error(), "secret-path")) {
+				t.Errorf("Send() error = %v, want redacted webhook error", err)
+			}
+			if len(rec.requests) != tt.requests {
+				t.Errorf("requests = %d, want %d", len(rec.requests), tt.requests)
+			}
+		})
+	}
+}
+
+func TestDelay(t *testing.T) {
+	n := NewNotifier(Options{})
+
+	tests := []struct {
+		attempt  int
+		expected time.Duration
+	}{
+		{0, defaultBackoff},
+		{1, 2 * defaultBackoff},
+		{3, 8 * defaultBackoff},
+		{10, maxBackoff},
+		{100, maxBackoff},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.attempt), func(t *testing.T) {
+			if got := n.delay(tt.attempt); got != tt.expected {
+				t.Errorf("delay(%d) = %v, want %v", tt.attempt, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	n := NewNotifier(Options{Webhooks: []Webhook{{Kind: KindJSON, URL: srv.URL}}, Timeout: 20 * time.Millisecond})
+
+	start := time.Now()
+	err := n.Send(context.Background(), Event{Event: EventCompleted})
+	if err == nil {
+		t.Fatal("Send() expected timeout error, got nil")
+	}
+	if time.Since(start) > time.Second {
+		t.Errorf("Send() took %v, want it to time out", time.Since(start))
+	}
+}
+
+func TestSendWithoutWebhooks(t *testing.T) {
+	var n *Notifier
+	if err := n.Send(context.Background(), Event{}); err != nil {
+		t.Errorf("Send() on nil notifier = %v, want nil", err)
+	}
+
+	n, err := New(types.Config{}, "1.0.0")
+	if err != nil || n.Send(context.Background(), Event{}) != nil {
+		t.Errorf("Send() without webhooks = %v, want nil", err)
+	}
+
+	if _, err := New(types.Config{Webhooks: []string{"not a url"}}, "1.0.0"); err == nil {
+		t.Errorf("New() expected error for invalid webhook, got nil")
+	}
+}
@@ -0,0 +1,95 @@
+package notify
+
+import (
+	"fmt"
+	"net"
+	"port-scanner/internal/hostmatch"
+	"port-scanner/internal/types"
+	"strconv"
+	"strings"
+)
+
+const (
+	maxListedPorts   = 20
+	teamsCardType    = "MessageCard"
+	teamsCardContext = "https://schema.org/extensions"
+	teamsThemeColor  = "0076D7"
+)
+
+type slackMessage struct {
+	Text string `json:"text"`
+}
+
+type teamsMessage struct {
+	Type       string `json:"@type"`
+	Context    string `json:"@context"`
+	ThemeColor string `json:"themeColor"`
+	Summary    string `json:"summary"`
+	Title      string `json:"title"`
+	Text       string `json:"text"`
+}
+
+func payload(kind Kind, event Event) any {
+	switch kind {
+	case KindSlack:
+		return slackMessage{Text: title(event) + "\n" + strings.Join(lines(event), "\n")}
+	case KindTeams:
+		return teamsMessage{
+			Type:       teamsCardType,
+			Context:    teamsCardContext,
+			ThemeColor: teamsThemeColor,
+			Summary:    title(event),
+			Title:      title(event),
+			Text:       strings.Join(lines(event), "\n\n"),
+		}
+	default:
+		return event
+	}
+}
+
+func title(event Event) string {
+	if event.Event == EventPortsOpened {
+		return fmt.Sprintf("Port scanner detected %d new open ports", len(event.Changes))
+	}
+
+	summary := types.Summary{Open: len(event.Open)}
+	if event.Summary != nil {
+		summary = *event.Summary
+	}
+	return fmt.Sprintf("Port scan finished: %d hosts, %d ports, %d open", summary.Hosts, summary.Ports, summary.Open)
+}
+
+func lines(event Event) []string {
+	ports := make([]string, 0)
+	if event.Event == EventPortsOpened {
+		for _, c := range event.Changes {
+			ports = append(ports, endpoint(c.Host, c.Port, ""))
+		}
+	} else {
+		for _, r := range event.Open {
+			ports = append(ports, endpoint(r.Host, r.Port, r.Service))
+		}
+	}
+
+	out := make([]string, 0, maxListedPorts+2)
+	for i, port := range ports {
+		if i == maxListedPorts {
+			out = append(out, fmt.Sprintf("… and %d more", len(ports)-maxListedPorts))
+			break
+		}
+		out = append(out, "• "+port)
+	}
+
+	if len(event.Violations) > 0 {
+		out = append(out, fmt.Sprintf("%d policy violations", len(event.Violations)))
+	}
+	return out
+}
+
+func endpoint(host string, port int, service string) string {
+	address := net.JoinHostPort(hostmatch.Name(host), strconv.Itoa(port))
+	if service == "" {
+		return address
+	}
+	return address + " " + service
+}
@@ -0,0 +1,83 @@
+package notify
+
+import (
+	"fmt"
+	"port-scanner/internal/diff"
+	"port-scanner/internal/types"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPayload(t *testing.T) {
+	completed := Event{
+		Event:      EventCompleted,
+		Summary:    &types.Summary{Hosts: 2, Ports: 1024, Open: 2},
+		Open:       []types.Result{{Host: "10.0.0.1", Port: 22, Status: true, Service: "ssh"}, {Host: "2001:db8::1", Port: 443, Status: true}, {Port: 80, Status: true}},
+		Violations: []types.Violation{{Host: "10.0.0.1", Port: 22, Rule: "port-not-allowed"}},
+	}
+	opened := Event{
+		Event:   EventPortsOpened,
+		Changes: []diff.Change{{Kind: diff.KindOpened, Host: "10.0.0.1", Port: 8080}},
+	}
+
+	tests := []struct {
+		name     string
+		kind     Kind
+		event    Event
+		expected any
+	}{
+		{"json", KindJSON, completed, completed},
+		{
+			name:  "slack completed",
+			kind:  KindSlack,
+			event: completed,
+			expected: slackMessage{
+				Text: "Port scan finished: 2 hosts, 1024 ports, 2 open\n• 10.0.0.1:22 ssh\n• [2001:db8::1]:443\n• -:80\n1 policy violations",
+			},
+		},
+		{
+			name:     "slack opened",
+			kind:     KindSlack,
+			event:    opened,
+			expected: slackMessage{Text: "Port scanner detected 1 new open ports\n• 10.0.0.1:8080"},
+		},
+		{
+			name:  "teams opened",
+			kind:  KindTeams,
+			event: opened,
+			expected: teamsMessage{
+				Type:       "MessageCard",
+				Context:    "https://schema.org/extensions",
+				ThemeColor: teamsThemeColor,
+				Summary:    "Port scanner detected 1 new open ports",
+				Title:      "Port scanner detected 1 new open ports",
+				Text:       "• 10.0.0.1:8080",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := payload(tt.kind, tt.event)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("payload() = %+v, want %+v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestLinesLimit(t *testing.T) {
+	event := Event{Event: EventCompleted}
+	for port := 1; port <= maxListedPorts+5; port++ {
+		event.Open = append(event.Open, types.Result{Host: "10.0.0.1", Port: port, Status: true})
+	}
+
+	got := lines(event)
+	if len(got) != maxListedPorts+1 {
+		t.Fatalf("lines() = %d lines, want %d", len(got), maxListedPorts+1)
+	}
+	if last := got[len(got)-1]; !strings.Contains(last, fmt.Sprintf("and %d more", 5)) {
+		t.Errorf("last line = %q, want the remaining count", last)
+	}
+}
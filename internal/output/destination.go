@@ -0,0 +1,95 @@
+package output
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"port-scanner/internal/types"
+	"strings"
+)
+
+const (
+	stdoutPath           = "-"
+	destinationSeparator = ":"
+)
+
+var (
+	invalidDestinationError = errors.New("invalid output: expected format:path")
+)
+
+type Destination struct {
+	Format Format
+	Path   string
+}
+
+func ParseDestination(s string) (Destination, error) {
+	name, path, ok := strings.Cut(strings.TrimSpace(s), destinationSeparator)
+	if !ok || strings.TrimSpace(path) == "" {
+		return Destination{}, fmt.Errorf("%w: %q", invalidDestinationError, s)
+	}
+
+	format, err := ParseFormat(name)
+	if err != nil {
+		return Destination{}, fmt.Errorf("%w: %q", invalidDestinationError, s)
+	}
+
+	return Destination{Format: format, Path: strings.TrimSpace(path)}, nil
+}
+
+func Destinations(cfg types.Config) ([]Destination, error) {
+	destinations := make([]Destination, 0, len(cfg.Outputs)+1)
+
+	if cfg.Output != "" || len(cfg.Outputs) == 0 {
+		format, err := ParseFormat(cfg.Format)
+		if err != nil {
+			format = FormatTxt
+		}
+		destinations = append(destinations, Destination{Format: format, Path: cfg.Output})
+	}
+
+	for _, s := range cfg.Outputs {
+		destination, err := ParseDestination(s)
+		if err != nil {
+			return nil, err
+		}
+		destinations = append(destinations, destination)
+	}
+
+	stdout := 0
+	for _, d := range destinations {
+		if d.Path == stdoutPath {
+			stdout++
+		}
+	}
+	if stdout > 1 {
+		return nil, fmt.Errorf("%w: only one output can be written to stdout", invalidDestinationError)
+	}
+
+	return destinations, nil
+}
+
+func (d Destination) write(report types.Report, cfg types.Config) error {
+	output, err := formatReport(report, d.Format, cfg)
+	if err != nil {
+		return err
+	}
+
+	if d.Path == stdoutPath {
+		if !strings.HasSuffix(output, "\n") {
+			output += "\n"
+		}
+		_, err = os.Stdout.WriteString(output)
+		if err != nil {
+			return writeFileError
+		}
+		return nil
+	}
+
+	outputPath := d.outputPath()
+	_, _ = fmt.Fprintln(os.Stderr, outputPath)
+	return writeToFile(outputPath, output)
+}
+
+func (d Destination) outputPath() string {
+	return generateOutputPath(d.Path, d.Format.Extension())
+}
@@ -0,0 +1,157 @@
+package output
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"port-scanner/internal/types"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseDestination(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Destination
+		wantErr  bool
+	}{
+		{"json:-", Destination{Format: FormatJson, Path: "-"}, false},
+		{"csv:results.csv", Destination{Format: FormatCsv, Path: "results.csv"}, false},
+		{"TXT:out/scan", Destination{Format: FormatTxt, Path: "out/scan"}, false},
+		{"json:/tmp/a:b.json", Destination{Format: FormatJson, Path: "/tmp/a:b.json"}, false},
+		{"results.json", Destination{}, true},
+		{"xls:results.xls", Destination{}, true},
+		{"json:", Destination{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseDestination(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseDestination(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if tt.wantErr && !errors.Is(err, invalidDestinationError) {
+				t.Errorf("ParseDestination(%q) error = %v, want %v", tt.input, err, invalidDestinationError)
+			}
+			if got != tt.expected {
+				t.Errorf("ParseDestination(%q) = %+v, want %+v", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDestinations(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      types.Config
+		expected []Destination
+		wantErr  bool
+	}{
+		{
+			name:     "default generated file",
+			cfg:      types.Config{Format: "txt"},
+			expected: []Destination{{Format: FormatTxt}},
+		},
+		{
+			name:     "stdout",
+			cfg:      types.Config{Format: "json", Output: "-"},
+			expected: []Destination{{Format: FormatJson, Path: "-"}},
+		},
+		{
+			name:     "additional outputs replace the generated file",
+			cfg:      types.Config{Format: "txt", Outputs: []string{"json:-", "csv:results.csv"}},
+			expected: []Destination{{Format: FormatJson, Path: "-"}, {Format: FormatCsv, Path: "results.csv"}},
+		},
+		{
+			name:     "output and additional outputs",
+			cfg:      types.Config{Format: "txt", Output: "scan", Outputs: []string{"json:-"}},
+			expected: []Destination{{Format: FormatTxt, Path: "scan"}, {Format: FormatJson, Path: "-"}},
+		},
+		{
+			name:    "invalid additional output",
+			cfg:     types.Config{Outputs: []string{"results.csv"}},
+			wantErr: true,
+		},
+		{
+			name:    "stdout used twice",
+			cfg:     types.Config{Format: "txt", Output: "-", Outputs: []string{"json:-"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Destinations(tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Destinations() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Destinations() = %+v, want %+v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestExportDestinations(t *testing.T) {
+	tempDir := t.TempDir()
+	csvPath := filepath.Join(tempDir, "results.csv")
+	txtPath := filepath.Join(tempDir, "results.txt")
+
+	stdout := captureStdout(t, func() {
+		err := Export(types.Report{Results: testResults}, types.Config{
+			Format:  "json",
+			Output:  "-",
+			Outputs: []string{"csv:" + csvPath, "txt:" + txtPath},
+		})
+		if err != nil {
+			t.Fatalf("Export() error = %v", err)
+		}
+	})
+
+	fromStdout, err := Parse([]byte(stdout), FormatJson)
+	if err != nil {
+		t.Fatalf("stdout is not valid json: %v\n%s", err, stdout)
+	}
+	if !reflect.DeepEqual(fromStdout, testResults) {
+		t.Errorf("stdout results = %+v, want %+v", fromStdout, testResults)
+	}
+
+	for _, path := range []string{csvPath, txtPath} {
+		results, err := Load(path)
+		if err != nil {
+			t.Fatalf("Load(%q) error = %v", path, err)
+		}
+		if len(results) != len(testResults) {
+			t.Errorf("Load(%q) = %d results, want %d", path, len(results), len(testResults))
+		}
+		if strings.Contains(stdout, path) {
+			t.Errorf("stdout mentions output path %q", path)
+		}
+	}
+}
+
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("os.Pipe() error = %v", err)
+	}
+
+	original := os.Stdout
+	os.Stdout = w
+	defer func() {
+		os.Stdout = original
+	}()
+
+	fn()
+	_ = w.Close()
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("io.ReadAll() error = %v", err)
+	}
+	return string(data)
+}
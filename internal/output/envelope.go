@@ -0,0 +1,77 @@
+package output
+
+import (
+	"encoding/json"
+	"port-scanner/internal/types"
+	"time"
+)
+
+const (
+	jsonSchemaVersion = 1
+	toolName          = "port-scanner"
+)
+
+type envelope struct {
+	SchemaVersion int               `json:"schema_version"`
+	Tool          envelopeTool      `json:"tool"`
+	CommandLine   []string          `json:"command_line"`
+	Scan          envelopeScan      `json:"scan"`
+	Summary       types.Summary     `json:"summary"`
+	Results       []types.Result    `json:"results"`
+	Violations    []types.Violation `json:"violations,omitempty"`
+}
+
+type envelopeTool struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type envelopeScan struct {
+	Targets     []string  `json:"targets"`
+	Ports       string    `json:"ports"`
+	Input       string    `json:"input,omitempty"`
+	Shard       string    `json:"shard,omitempty"`
+	Mode        string    `json:"mode"`
+	Timeout     int64     `json:"timeout_ms"`
+	Concurrency int       `json:"concurrency"`
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end"`
+	Duration    float64   `json:"duration_seconds"`
+}
+
+func toJSONEnvelope(report types.Report) (string, error) {
+	metadata := report.Metadata
+	e := envelope{
+		SchemaVersion: jsonSchemaVersion,
+		Tool:          envelopeTool{Name: toolName, Version: metadata.Version},
+		CommandLine:   nonNil(metadata.Args),
+		Scan: envelopeScan{
+			Targets:     nonNil(metadata.Targets),
+			Ports:       metadata.Ports,
+			Input:       metadata.Input,
+			Shard:       metadata.Shard,
+			Mode:        metadata.Mode,
+			Timeout:     metadata.Timeout.Milliseconds(),
+			Concurrency: metadata.Concurrency,
+			Start:       metadata.Start,
+			End:         metadata.End,
+			Duration:    metadata.End.Sub(metadata.Start).Seconds(),
+		},
+		Summary:    report.Summary,
+		Results:    nonNil(report.Results),
+		Violations: report.Violations,
+	}
+
+	data, err := json.MarshalIndent(e, "", "  ")
+	if err != nil {
+		return "", writeFileError
+	}
+	return string(data), nil
+}
+
+func nonNil[T any](s []T) []T {
+	if s == nil {
+		return []T{}
+	}
+	return s
+}
@@ -0,0 +1,155 @@
+package output
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"port-scanner/internal/types"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestToJSONEnvelope(t *testing.T) {
+	start := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	report := types.Report{
+		Results: testResults,
+		Summary: types.Summary{Hosts: 1, Ports: 3, Scanned: 3, Open: 2},
+		Metadata: types.Metadata{
+			Version:     "1.0.0",
+			Args:        []string{"port-scanner", "-a", "10.0.0.1", "-f", "json"},
+			Targets:     []string{"10.0.0.1"},
+			Ports:       "80,443,8080",
+			Mode:        "default",
+			Timeout:     time.Second,
+			Concurrency: 100,
+			Start:       start,
+			End:         start.Add(1500 * time.Millisecond),
+		},
+	}
+
+	output, err := toJSONEnvelope(report)
+	if err != nil {
+		t.Fatalf("toJSONEnvelope() error = %v", err)
+	}
+
+	var got map[string]any
+	if err := json.Unmarshal([]byte(output), &got); err != nil {
+		t.Fatalf("toJSONEnvelope() produced invalid JSON: %v", err)
+	}
+
+	expected := map[string]any{
+		"schema_version": float64(1),
+		"tool":           map[string]any{"name": "port-scanner", "version": "1.0.0"},
+		"command_line":   []any{"port-scanner", "-a", "10.0.0.1", "-f", "json"},
+		"scan": map[string]any{
+			"targets":          []any{"10.0.0.1"},
+			"ports":            "80,443,8080",
+			"mode":             "default",
+			"timeout_ms":       float64(1000),
+			"concurrency":      float64(100),
+			"start":            "2026-01-02T03:04:05Z",
+			"end":              "2026-01-02T03:04:06.5Z",
+			"duration_seconds": 1.5,
+		},
+		"summary": map[string]any{
+			"hosts": float64(1), "ports": float64(3), "scanned": float64(3), "open": float64(2),
+			"excluded_hosts": float64(0), "excluded_ports": float64(0),
+		},
+	}
+	for key, want := range expected {
+		if !reflect.DeepEqual(got[key], want) {
+			t.Errorf("%s = %v, want %v", key, got[key], want)
+		}
+	}
+
+	results, err := Parse([]byte(output), FormatJson)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if !reflect.DeepEqual(results, testResults) {
+		t.Errorf("Parse() = %+v, want %+v", results, testResults)
+	}
+}
+
+func TestToJSONEnvelopeEmpty(t *testing.T) {
+	output, err := toJSONEnvelope(types.Report{})
+	if err != nil {
+		t.Fatalf("toJSONEnvelope() error = %v", err)
+	}
+
+	for _, want := range []string{`"results": []`, `"command_line": []`, `"targets": []`} {
+		if !strings.Contains(output, want) {
+			t.Errorf("toJSONEnvelope() missing %q in:\n%s", want, output)
+		}
+	}
+}
+
+func TestParseEnvelope(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		detected Format
+		wantErr  bool
+	}{
+		{"envelope", `{"schema_version":1,"results":[{"port":80,"status":true}]}`, FormatJson, false},
+		{"indented envelope", "{\n  \"schema_version\": 1,\n  \"results\": [\n    {\"port\": 80, \"status\": true}\n  ]\n}", FormatJson, false},
+		{"single ndjson result", `{"port":80,"status":true}`, FormatNdjson, false},
+		{"newer schema version", `{"schema_version":2,"results":[]}`, FormatJson, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			format := detectFormat([]byte(tt.data))
+			if format != tt.detected {
+				t.Fatalf("detectFormat() = %v, want %v", format, tt.detected)
+			}
+
+			results, err := Parse([]byte(tt.data), format)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(results, []types.Result{{Port: 80, Status: true}}) {
+				t.Errorf("Parse() = %+v", results)
+			}
+		})
+	}
+}
+
+func TestExportJSONLegacy(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name   string
+		legacy bool
+		prefix string
+	}{
+		{"envelope by default", false, "{"},
+		{"legacy array", true, "["},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout := captureStdout(t, func() {
+				err := Export(types.Report{Results: testResults}, types.Config{Format: "json", Output: "-", JsonLegacy: tt.legacy})
+				if err != nil {
+					t.Fatalf("Export() error = %v", err)
+				}
+			})
+
+			if !strings.HasPrefix(stdout, tt.prefix) {
+				t.Errorf("Export() = %q, want prefix %q", stdout, tt.prefix)
+			}
+
+			path := filepath.Join(dir, strings.ReplaceAll(tt.name, " ", "_")+".json")
+			err := Export(types.Report{Results: testResults}, types.Config{Format: "json", Output: path, JsonLegacy: tt.legacy})
+			if err != nil {
+				t.Fatalf("Export() error = %v", err)
+			}
+			results, err := Load(path)
+			if err != nil || !reflect.DeepEqual(results, testResults) {
+				t.Errorf("Load() = %+v, %v", results, err)
+			}
+		})
+	}
+}
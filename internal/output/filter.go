@@ -0,0 +1,306 @@
+package output
+
+import (
+	"errors"
+	"fmt"
+	"port-scanner/internal/types"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const (
+	fieldHost    = "host"
+	fieldPort    = "port"
+	fieldStatus  = "status"
+	fieldService = "service"
+	fieldBanner  = "banner"
+	statusOpen   = "open"
+	statusClosed = "closed"
+)
+
+var (
+	invalidFilterError = errors.New("invalid filter")
+)
+
+type Filter func(result types.Result) bool
+
+type filterParser struct {
+	tokens []string
+	pos    int
+}
+
+func NewFilter(cfg types.Config) (Filter, error) {
+	filter, err := ParseFilter(cfg.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if !cfg.OpenOnly {
+		return filter, nil
+	}
+
+	return func(result types.Result) bool {
+		return result.Status && filter(result)
+	}, nil
+}
+
+func ParseFilter(expr string) (Filter, error) {
+	if strings.TrimSpace(expr) == "" {
+		return func(types.Result) bool { return true }, nil
+	}
+
+	tokens, err := tokenizeFilter(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &filterParser{tokens: tokens}
+	filter, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("%w: unexpected %q", invalidFilterError, p.tokens[p.pos])
+	}
+
+	return filter, nil
+}
+
+func applyFilter(results []types.Result, filter Filter) []types.Result {
+	filtered := make([]types.Result, 0, len(results))
+	for _, result := range results {
+		if filter(result) {
+			filtered = append(filtered, result)
+		}
+	}
+	return filtered
+}
+
+func tokenizeFilter(expr string) ([]string, error) {
+	tokens := make([]string, 0)
+	runes := []rune(expr)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(' || r == ')':
+			tokens = append(tokens, string(r))
+			i++
+		case r == '"':
+			end := i + 1
+			for end < len(runes) && runes[end] != '"' {
+				if runes[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(runes) {
+				return nil, fmt.Errorf("%w: unterminated string", invalidFilterError)
+			}
+			tokens = append(tokens, string(runes[i:end+1]))
+			i = end + 1
+		case strings.ContainsRune("=!<>&|~", r):
+			end := i + 1
+			for end < len(runes) && strings.ContainsRune("=!<>&|~", runes[end]) && end-i < 2 {
+				end++
+			}
+			tokens = append(tokens, string(runes[i:end]))
+			i = end
+		default:
+			end := i
+			for end < len(runes) && !unicode.IsSpace(runes[end]) && !strings.ContainsRune("()\"=!<>&|~", runes[end]) {
+				end++
+			}
+			tokens = append(tokens, string(runes[i:end]))
+			i = end
+		}
+	}
+
+	return tokens, nil
+}
+
+func (p *filterParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *filterParser) next() (string, error) {
+	if p.pos >= len(p.tokens) {
+		return "", fmt.Errorf("%w: unexpected end of expression", invalidFilterError)
+	}
+	token := p.tokens[p.pos]
+	p.pos++
+	return token, nil
+}
+
+func (p *filterParser) parseOr() (Filter, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek() == "||" {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(result types.Result) bool { return l(result) || right(result) }
+	}
+
+	return left, nil
+}
+
+func (p *filterParser) parseAnd() (Filter, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek() == "&&" {
+		p.pos++
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(result types.Result) bool { return l(result) && right(result) }
+	}
+
+	return left, nil
+}
+
+func (p *filterParser) parseUnary() (Filter, error) {
+	switch p.peek() {
+	case "!":
+		p.pos++
+		inner, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return func(result types.Result) bool { return !inner(result) }, nil
+	case "(":
+		p.pos++
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if token, _ := p.next(); token != ")" {
+			return nil, fmt.Errorf("%w: missing closing parenthesis", invalidFilterError)
+		}
+		return inner, nil
+	default:
+		return p.parseComparison()
+	}
+}
+
+func (p *filterParser) parseComparison() (Filter, error) {
+	field, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+
+	operator, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	value = unquote(value)
+
+	switch strings.ToLower(field) {
+	case fieldPort:
+		return compareInt(operator, value, func(r types.Result) int { return r.Port })
+	case fieldStatus:
+		return compareStatus(operator, value)
+	case fieldHost:
+		return compareString(operator, value, func(r types.Result) string { return r.Host })
+	case fieldService:
+		return compareString(operator, value, func(r types.Result) string { return r.Service })
+	case fieldBanner:
+		return compareString(operator, value, func(r types.Result) string { return r.Banner })
+	default:
+		return nil, fmt.Errorf("%w: unknown field %q", invalidFilterError, field)
+	}
+}
+
+func unquote(value string) string {
+	if len(value) < 2 || !strings.HasPrefix(value, `"`) || !strings.HasSuffix(value, `"`) {
+		return value
+	}
+	return strings.ReplaceAll(value[1:len(value)-1], `\"`, `"`)
+}
+
+func compareInt(operator, value string, get func(types.Result) int) (Filter, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: expected number, got %q", invalidFilterError, value)
+	}
+
+	switch operator {
+	case "==":
+		return func(r types.Result) bool { return get(r) == n }, nil
+	case "!=":
+		return func(r types.Result) bool { return get(r) != n }, nil
+	case "<":
+		return func(r types.Result) bool { return get(r) < n }, nil
+	case "<=":
+		return func(r types.Result) bool { return get(r) <= n }, nil
+	case ">":
+		return func(r types.Result) bool { return get(r) > n }, nil
+	case ">=":
+		return func(r types.Result) bool { return get(r) >= n }, nil
+	default:
+		return nil, fmt.Errorf("%w: unsupported operator %q for numbers", invalidFilterError, operator)
+	}
+}
+
+func compareStatus(operator, value string) (Filter, error) {
+	var status bool
+	switch strings.ToLower(value) {
+	case statusOpen, "true":
+		status = true
+	case statusClosed, "false":
+		status = false
+	default:
+		return nil, fmt.Errorf("%w: expected open or closed, got %q", invalidFilterError, value)
+	}
+
+	switch operator {
+	case "==":
+		return func(r types.Result) bool { return r.Status == status }, nil
+	case "!=":
+		return func(r types.Result) bool { return r.Status != status }, nil
+	default:
+		return nil, fmt.Errorf("%w: unsupported operator %q for status", invalidFilterError, operator)
+	}
+}
+
+func compareString(operator, value string, get func(types.Result) string) (Filter, error) {
+	switch operator {
+	case "==":
+		return func(r types.Result) bool { return get(r) == value }, nil
+	case "!=":
+		return func(r types.Result) bool { return get(r) != value }, nil
+	case "=~", "!~":
+		re, err := regexp.Compile(value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid regular expression %q", invalidFilterError, value)
+		}
+		match := operator == "=~"
+		return func(r types.Result) bool { return re.MatchString(get(r)) == match }, nil
+	default:
+		return nil, fmt.Errorf("%w: unsupported operator %q for strings", invalidFilterError, operator)
+	}
+}
@@ -0,0 +1,119 @@
+package output
+
+import (
+	"errors"
+	"port-scanner/internal/types"
+	"reflect"
+	"testing"
+)
+
+var filterResults = []types.Result{
+	{Host: "10.0.0.1", Port: 22, Status: true, Service: "ssh", Banner: "SSH-2.0-OpenSSH_9.6"},
+	{Host: "10.0.0.1", Port: 80, Status: true, Service: "http"},
+	{Host: "10.0.0.1", Port: 443, Status: false},
+	{Host: "10.0.0.2", Port: 8080, Status: true, Service: "http-proxy"},
+	{Host: "10.0.0.2", Port: 9999, Status: false},
+}
+
+func TestParseFilter(t *testing.T) {
+	tests := []struct {
+		name     string
+		expr     string
+		expected []int
+	}{
+		{"empty expression matches everything", "", []int{22, 80, 443, 8080, 9999}},
+		{"status open", "status == open", []int{22, 80, 8080}},
+		{"status closed", "status != true", []int{443, 9999}},
+		{"open privileged ports", "status == open && port < 1024", []int{22, 80}},
+		{"port comparisons", "port >= 443 && port <= 8080", []int{443, 8080}},
+		{"service regex", `service =~ "http"`, []int{80, 8080}},
+		{"service not matching", `status == open && service !~ "^http"`, []int{22}},
+		{"host equality", "host == 10.0.0.2", []int{8080, 9999}},
+		{"banner regex", `banner =~ "OpenSSH"`, []int{22}},
+		{"regex with backslash", `banner =~ "SSH-\d\.\d"`, []int{22}},
+		{"escaped quote", `banner !~ "\"" && port < 100`, []int{22, 80}},
+		{"or", "port == 22 || port == 9999", []int{22, 9999}},
+		{"and binds tighter than or", "port == 22 || port > 1000 && status == open", []int{22, 8080}},
+		{"parentheses", "(port == 22 || port > 1000) && status == closed", []int{9999}},
+		{"negation", "!(status == open)", []int{443, 9999}},
+		{"no spaces", "port<100&&status==open", []int{22, 80}},
+		{"case insensitive fields", "PORT == 80", []int{80}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := ParseFilter(tt.expr)
+			if err != nil {
+				t.Fatalf("ParseFilter(%q) error = %v", tt.expr, err)
+			}
+
+			got := make([]int, 0)
+			for _, r := range applyFilter(filterResults, filter) {
+				got = append(got, r.Port)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("ParseFilter(%q) matched %v, want %v", tt.expr, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseFilterErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		expr string
+	}{
+		{"unknown field", "proto == tcp"},
+		{"missing value", "port =="},
+		{"missing operator", "port"},
+		{"invalid number", "port < abc"},
+		{"invalid status", "status == maybe"},
+		{"unsupported status operator", "status < open"},
+		{"unsupported string operator", "host > a"},
+		{"unsupported number operator", "port =~ 80"},
+		{"invalid regex", `service =~ "("`},
+		{"unterminated string", `service == "http`},
+		{"missing closing parenthesis", "(port == 22"},
+		{"trailing tokens", "port == 22 port == 80"},
+		{"dangling and", "port == 22 &&"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseFilter(tt.expr)
+			if !errors.Is(err, invalidFilterError) {
+				t.Errorf("ParseFilter(%q) error = %v, want %v", tt.expr, err, invalidFilterError)
+			}
+		})
+	}
+}
+
+func TestNewFilter(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      types.Config
+		expected []int
+	}{
+		{"no filter", types.Config{}, []int{22, 80, 443, 8080, 9999}},
+		{"open only", types.Config{OpenOnly: true}, []int{22, 80, 8080}},
+		{"open only with filter", types.Config{OpenOnly: true, Filter: "port > 100"}, []int{8080}},
+		{"filter only", types.Config{Filter: "port > 100"}, []int{443, 8080, 9999}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := NewFilter(tt.cfg)
+			if err != nil {
+				t.Fatalf("NewFilter() error = %v", err)
+			}
+
+			got := make([]int, 0)
+			for _, r := range applyFilter(filterResults, filter) {
+				got = append(got, r.Port)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("NewFilter() matched %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
@@ -8,24 +8,63 @@ import (
 type Format string
 
 const (
-	FormatCsv  Format = "csv"
-	FormatJson Format = "json"
-	FormatTxt  Format = "txt"
+	FormatCsv      Format = "csv"
+	FormatJson     Format = "json"
+	FormatTxt      Format = "txt"
+	FormatXml      Format = "xml"
+	FormatGrep     Format = "grep"
+	FormatHtml     Format = "html"
+	FormatMarkdown Format = "md"
+	FormatNdjson   Format = "ndjson"
+	FormatJunit    Format = "junit"
+	FormatProm     Format = "prom"
 )
 
 type metadata struct {
-	extension string
+	extension   string
+	contentType string
 }
 
 var metadataMap = map[Format]metadata{
 	FormatCsv: {
-		extension: ".csv",
+		extension:   ".csv",
+		contentType: "text/csv; charset=utf-8",
 	},
 	FormatJson: {
-		extension: ".json",
+		extension:   ".json",
+		contentType: "application/json",
 	},
 	FormatTxt: {
-		extension: ".txt",
+		extension:   ".txt",
+		contentType: "text/plain; charset=utf-8",
+	},
+	FormatXml: {
+		extension:   ".xml",
+		contentType: "application/xml",
+	},
+	FormatGrep: {
+		extension:   ".gnmap",
+		contentType: "text/plain; charset=utf-8",
+	},
+	FormatHtml: {
+		extension:   ".html",
+		contentType: "text/html; charset=utf-8",
+	},
+	FormatMarkdown: {
+		extension:   ".md",
+		contentType: "text/markdown; charset=utf-8",
+	},
+	FormatNdjson: {
+		extension:   ".ndjson",
+		contentType: "application/x-ndjson",
+	},
+	FormatJunit: {
+		extension:   ".junit.xml",
+		contentType: "application/xml",
+	},
+	FormatProm: {
+		extension:   ".prom",
+		contentType: "text/plain; version=0.0.4; charset=utf-8",
 	},
 }
 
@@ -33,6 +72,10 @@ func (f Format) Extension() string {
 	return metadataMap[f].extension
 }
 
+func (f Format) ContentType() string {
+	return metadataMap[f].contentType
+}
+
 func ParseFormat(s string) (Format, error) {
 	switch strings.ToLower(s) {
 	case "csv":
@@ -41,6 +84,20 @@ func ParseFormat(s string) (Format, error) {
 		return FormatJson, nil
 	case "txt":
 		return FormatTxt, nil
+	case "xml":
+		return FormatXml, nil
+	case "grep":
+		return FormatGrep, nil
+	case "html":
+		return FormatHtml, nil
+	case "md", "markdown":
+		return FormatMarkdown, nil
+	case "ndjson":
+		return FormatNdjson, nil
+	case "junit":
+		return FormatJunit, nil
+	case "prom", "prometheus":
+		return FormatProm, nil
 	default:
 		return "", fmt.Errorf("invalid format: %q", s)
 	}
@@ -13,6 +13,13 @@ func TestFormat_Extension(t *testing.T) {
 		{"CSV format", FormatCsv, ".csv"},
 		{"JSON format", FormatJson, ".json"},
 		{"TXT format", FormatTxt, ".txt"},
+		{"XML format", FormatXml, ".xml"},
+		{"Grep format", FormatGrep, ".gnmap"},
+		{"HTML format", FormatHtml, ".html"},
+		{"Markdown format", FormatMarkdown, ".md"},
+		{"NDJSON format", FormatNdjson, ".ndjson"},
+		{"JUnit format", FormatJunit, ".junit.xml"},
+		{"Prometheus format", FormatProm, ".prom"},
 	}
 
 	for _, tt := range tests {
@@ -37,7 +44,17 @@ func TestParseFormat(t *testing.T) {
 		{"JSON", FormatJson, false},
 		{"txt", FormatTxt, false},
 		{"TXT", FormatTxt, false},
-		{"xml", "", true},
+		{"xml", FormatXml, false},
+		{"XML", FormatXml, false},
+		{"grep", FormatGrep, false},
+		{"html", FormatHtml, false},
+		{"md", FormatMarkdown, false},
+		{"markdown", FormatMarkdown, false},
+		{"ndjson", FormatNdjson, false},
+		{"junit", FormatJunit, false},
+		{"prom", FormatProm, false},
+		{"prometheus", FormatProm, false},
+		{"yaml", "", true},
 		{"", "", true},
 		{"unknown", "", true},
 	}
@@ -0,0 +1,71 @@
+package output
+
+import (
+	"fmt"
+	"net/netip"
+	"port-scanner/internal/hostmatch"
+	"port-scanner/internal/types"
+	"strings"
+)
+
+const (
+	grepProtocol      = "tcp"
+	grepStateOpen     = "open"
+	grepStateClosed   = "closed"
+	grepStateFiltered = "filtered"
+)
+
+var grepFieldReplacer = strings.NewReplacer("/", "|", ",", ";")
+
+func toGrep(results []types.Result, violations ...types.Violation) string {
+	hosts := make([]string, 0)
+	ports := make(map[string][]string)
+	labels := make(map[string]string)
+	policy := make(map[string][]string)
+
+	for _, r := range results {
+		if _, ok := ports[r.Host]; !ok {
+			hosts = append(hosts, r.Host)
+			labels[r.Host] = grepHost(r)
+		}
+		ports[r.Host] = append(ports[r.Host], grepPort(r))
+	}
+
+	for _, v := range violations {
+		policy[v.Host] = append(policy[v.Host], fmt.Sprintf("%d/%s", v.Port, v.Rule))
+	}
+
+	var sb strings.Builder
+	for _, host := range hosts {
+		sb.WriteString(fmt.Sprintf("Host: %s\tPorts: %s", labels[host], strings.Join(ports[host], ", ")))
+		if len(policy[host]) > 0 {
+			sb.WriteString(fmt.Sprintf("\tPolicy: %s", strings.Join(policy[host], ", ")))
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+func grepHost(r types.Result) string {
+	if _, err := netip.ParseAddr(r.Host); err == nil {
+		return r.Host + " ()"
+	}
+
+	if addr, ok := resultAddress(r); ok {
+		return fmt.Sprintf("%s (%s)", addr, r.Host)
+	}
+	return fmt.Sprintf("%s (%s)", hostmatch.Name(r.Host), r.Host)
+}
+
+func grepPort(r types.Result) string {
+	state := grepStateClosed
+	if r.Status {
+		state = grepStateOpen
+	}
+	if r.Skipped {
+		state = grepStateFiltered
+	}
+
+	return fmt.Sprintf("%d/%s/%s//%s//%s/",
+		r.Port, state, grepProtocol, grepFieldReplacer.Replace(r.Service), grepFieldReplacer.Replace(r.Banner))
+}
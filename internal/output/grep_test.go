@@ -0,0 +1,58 @@
+package output
+
+import (
+	"port-scanner/internal/types"
+	"testing"
+)
+
+func TestToGrep(t *testing.T) {
+	tests := []struct {
+		name     string
+		results  []types.Result
+		expected string
+	}{
+		{
+			name:     "empty results",
+			results:  []types.Result{},
+			expected: "",
+		},
+		{
+			name: "one line per host",
+			results: []types.Result{
+				{Host: "10.0.0.1", Port: 22, Status: true, Service: "ssh"},
+				{Host: "10.0.0.2", Port: 80, Status: false},
+				{Host: "10.0.0.1", Port: 23, Status: false},
+			},
+			expected: "Host: 10.0.0.1 ()\tPorts: 22/open/tcp//ssh///, 23/closed/tcp/////\n" +
+				"Host: 10.0.0.2 ()\tPorts: 80/closed/tcp/////\n",
+		},
+		{
+			name:     "resolved hostname",
+			results:  []types.Result{{Host: "example.com", Address: "93.184.216.34", Port: 443, Status: true, Service: "https"}},
+			expected: "Host: 93.184.216.34 (example.com)\tPorts: 443/open/tcp//https///\n",
+		},
+		{
+			name:     "unresolved hostname",
+			results:  []types.Result{{Host: "db.internal", Port: 5432, Status: true, Service: "postgresql"}},
+			expected: "Host: db.internal (db.internal)\tPorts: 5432/open/tcp//postgresql///\n",
+		},
+		{
+			name:     "skipped port is filtered",
+			results:  []types.Result{{Host: "10.0.0.1", Port: 22, Skipped: true}},
+			expected: "Host: 10.0.0.1 ()\tPorts: 22/filtered/tcp/////\n",
+		},
+		{
+			name:     "banner separators are escaped",
+			results:  []types.Result{{Host: "10.0.0.1", Port: 80, Status: true, Service: "http", Banner: "HTTP/1.1 200 OK, nginx"}},
+			expected: "Host: 10.0.0.1 ()\tPorts: 80/open/tcp//http//HTTP|1.1 200 OK; nginx/\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := toGrep(tt.results); got != tt.expected {
+				t.Errorf("toGrep() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,200 @@
+package output
+
+import (
+	"fmt"
+	"html/template"
+	"port-scanner/internal/hostmatch"
+	"port-scanner/internal/types"
+	"strings"
+	"time"
+)
+
+const (
+	htmlTimeFormat    = time.RFC1123
+	htmlStatusOpen    = "open"
+	htmlStatusClosed  = "closed"
+	htmlStatusSkipped = "skipped"
+)
+
+var htmlTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Port scan report</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Roboto, sans-serif; margin: 2rem; color: #1f2328; background: #fff; }
+h1 { font-size: 1.6rem; margin-bottom: .25rem; }
+h2 { font-size: 1.2rem; margin: 2rem 0 .5rem; }
+.meta { color: #59636e; font-size: .9rem; }
+.meta code { background: #f6f8fa; padding: .1rem .3rem; border-radius: 4px; }
+.cards { display: flex; gap: 1rem; margin: 1.5rem 0; flex-wrap: wrap; }
+.card { border: 1px solid #d1d9e0; border-radius: 8px; padding: .75rem 1.25rem; min-width: 8rem; }
+.card b { display: block; font-size: 1.5rem; }
+.controls { display: flex; gap: 1rem; align-items: center; margin-bottom: 1rem; }
+.controls input[type=search] { padding: .4rem .6rem; border: 1px solid #d1d9e0; border-radius: 6px; width: 20rem; }
+table { border-collapse: collapse; width: 100%; font-size: .9rem; }
+th, td { text-align: left; padding: .4rem .6rem; border-bottom: 1px solid #d1d9e0; }
+th { background: #f6f8fa; cursor: pointer; user-select: none; }
+th:after { content: " \2195"; color: #afb8c1; }
+td.banner { font-family: ui-monospace, monospace; word-break: break-all; }
+.open { color: #1a7f37; font-weight: 600; }
+.closed { color: #8c959f; }
+.skipped { color: #9a6700; }
+.violations h2, .card.violation b { color: #d1242f; }
+tr.hidden, section.hidden { display: none; }
+</style>
+</head>
+<body>
+<h1>Port scan report</h1>
+<p class="meta">{{if .Version}}port-scanner {{.Version}}{{end}}{{if .Start}} &middot; {{.Start}} &ndash; {{.End}} ({{.Duration}}){{end}}</p>
+{{if .Args}}<p class="meta"><code>{{.Args}}</code></p>{{end}}
+<div class="cards">
+<div class="card"><b>{{.HostCount}}</b>hosts</div>
+<div class="card"><b>{{.Ports}}</b>ports scanned</div>
+<div class="card"><b>{{.Open}}</b>open</div>
+{{if .Violations}}<div class="card violation"><b>{{len .Violations}}</b>policy violations</div>{{end}}
+</div>
+{{if .Violations}}<div class="violations">
+<h2>Policy violations</h2>
+<table>
+<thead><tr><th>Host</th><th data-type="number">Port</th><th>Rule</th><th>Message</th></tr></thead>
+<tbody>
+{{range .Violations}}<tr><td>{{.Host}}</td><td>{{.Port}}</td><td>{{.Rule}}</td><td>{{.Message}}</td></tr>
+{{end}}</tbody>
+</table>
+</div>
+{{end}}
+<div class="controls">
+<input type="search" id="filter" placeholder="Filter by port, service or banner">
+<label><input type="checkbox" id="open-only"> open only</label>
+</div>
+{{range .Hosts}}<section>
+<h2>{{.Host}} <span class="meta">{{.Open}} open</span></h2>
+<table>
+<thead><tr><th data-type="number">Port</th><th>Status</th><th>Service</th><th>Banner</th><th data-type="number">Latency (ms)</th></tr></thead>
+<tbody>
+{{range .Rows}}<tr data-status="{{.Status}}"><td>{{.Port}}</td><td class="{{.Status}}">{{.Status}}</td><td>{{.Service}}</td><td class="banner">{{.Banner}}</td><td>{{.Latency}}</td></tr>
+{{end}}</tbody>
+</table>
+</section>
+{{end}}<script>
+(function () {
+  var filter = document.getElementById("filter");
+  var openOnly = document.getElementById("open-only");
+
+  function apply() {
+    var term = filter.value.toLowerCase();
+    document.querySelectorAll("section").forEach(function (section) {
+      var visible = 0;
+      section.querySelectorAll("tbody tr").forEach(function (row) {
+        var show = row.textContent.toLowerCase().indexOf(term) !== -1 &&
+          (!openOnly.checked || row.dataset.status === "open");
+        row.classList.toggle("hidden", !show);
+        if (show) visible++;
+      });
+      section.classList.toggle("hidden", visible === 0);
+    });
+  }
+
+  document.querySelectorAll("th").forEach(function (th) {
+    th.addEventListener("click", function () {
+      var body = th.closest("table").tBodies[0];
+      var index = th.cellIndex;
+      var numeric = th.dataset.type === "number";
+      var asc = th.dataset.order !== "asc";
+      th.dataset.order = asc ? "asc" : "desc";
+      Array.from(body.rows).sort(function (a, b) {
+        var x = a.cells[index].textContent, y = b.cells[index].textContent;
+        var cmp = numeric ? (parseFloat(x) || 0) - (parseFloat(y) || 0) : x.localeCompare(y);
+        return asc ? cmp : -cmp;
+      }).forEach(function (row) { body.appendChild(row); });
+    });
+  });
+
+  filter.addEventListener("input", apply);
+  openOnly.addEventListener("change", apply);
+})();
+</script>
+</body>
+</html>
+`))
+
+type htmlReport struct {
+	Version    string
+	Args       string
+	Start      string
+	End        string
+	Duration   string
+	HostCount  int
+	Ports      int
+	Open       int
+	Hosts      []htmlHost
+	Violations []types.Violation
+}
+
+type htmlHost struct {
+	Host string
+	Open int
+	Rows []htmlRow
+}
+
+type htmlRow struct {
+	Port    int
+	Status  string
+	Service string
+	Banner  string
+	Latency string
+}
+
+func toHTML(report types.Report) (string, error) {
+	data := htmlReport{
+		Version:    report.Metadata.Version,
+		Args:       strings.Join(report.Metadata.Args, " "),
+		Hosts:      make([]htmlHost, 0),
+		Violations: make([]types.Violation, 0, len(report.Violations)),
+	}
+
+	data.HostCount, data.Ports = scannedCounts(report)
+	for _, v := range report.Violations {
+		v.Host = hostmatch.Name(v.Host)
+		data.Violations = append(data.Violations, v)
+	}
+
+	if !report.Metadata.Start.IsZero() {
+		data.Start = report.Metadata.Start.Format(htmlTimeFormat)
+		data.End = report.Metadata.End.Format(htmlTimeFormat)
+		data.Duration = report.Metadata.End.Sub(report.Metadata.Start).Round(time.Millisecond).String()
+	}
+
+	index := make(map[string]int)
+	for _, r := range report.Results {
+		i, ok := index[r.Host]
+		if !ok {
+			i = len(data.Hosts)
+			index[r.Host] = i
+			data.Hosts = append(data.Hosts, htmlHost{Host: hostmatch.Name(r.Host)})
+		}
+
+		row := htmlRow{Port: r.Port, Status: htmlStatusClosed, Service: r.Service, Banner: r.Banner}
+		if r.Status {
+			row.Status = htmlStatusOpen
+			data.Open++
+			data.Hosts[i].Open++
+		}
+		if r.Skipped {
+			row.Status = htmlStatusSkipped
+		}
+		if r.Latency > 0 {
+			row.Latency = fmt.Sprintf("%.2f", r.Latency)
+		}
+		data.Hosts[i].Rows = append(data.Hosts[i].Rows, row)
+	}
+
+	var sb strings.Builder
+	err := htmlTemplate.Execute(&sb, data)
+	if err != nil {
+		return "", writeFileError
+	}
+	return sb.String(), nil
+}
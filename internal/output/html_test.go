@@ -0,0 +1,100 @@
+package output
+
+import (
+	"port-scanner/internal/types"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestToHTML(t *testing.T) {
+	start := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		report      types.Report
+		contains    []string
+		notContains []string
+	}{
+		{
+			name:     "empty report",
+			report:   types.Report{},
+			contains: []string{"<!DOCTYPE html>", "<b>0</b>hosts", "<b>0</b>ports scanned", "<b>0</b>open"},
+		},
+		{
+			name: "hosts, summary and metadata",
+			report: types.Report{
+				Results: []types.Result{
+					{Host: "10.0.0.1", Port: 22, Status: true, Service: "ssh", Banner: "SSH-2.0-OpenSSH_9.6", Latency: 1.234},
+					{Host: "10.0.0.1", Port: 23},
+					{Host: "10.0.0.1", Port: 25, Skipped: true},
+					{Host: "10.0.0.2", Port: 80, Status: true, Service: "http", Latency: 12.5},
+				},
+				Metadata: types.Metadata{
+					Version: "1.0.0",
+					Args:    []string{"port-scanner", "-a", "10.0.0.1,10.0.0.2"},
+					Start:   start,
+					End:     start.Add(90 * time.Second),
+				},
+			},
+			contains: []string{
+				"port-scanner 1.0.0",
+				"<code>port-scanner -a 10.0.0.1,10.0.0.2</code>",
+				"(1m30s)",
+				"<b>2</b>hosts",
+				"<b>4</b>ports scanned",
+				"<b>2</b>open",
+				`<h2>10.0.0.1 <span class="meta">1 open</span></h2>`,
+				`<h2>10.0.0.2 <span class="meta">1 open</span></h2>`,
+				`<tr data-status="open"><td>22</td><td class="open">open</td><td>ssh</td><td class="banner">SSH-2.0-OpenSSH_9.6</td><td>1.23</td></tr>`,
+				`<tr data-status="closed"><td>23</td><td class="closed">closed</td><td></td><td class="banner"></td><td></td></tr>`,
+				`<tr data-status="skipped"><td>25</td><td class="skipped">skipped</td><td></td><td class="banner"></td><td></td></tr>`,
+				`<td>12.50</td>`,
+			},
+		},
+		{
+			name: "scanned counts from the summary",
+			report: types.Report{
+				Results: []types.Result{{Host: "10.0.0.1", Port: 22, Status: true}},
+				Summary: types.Summary{Hosts: 4, Ports: 100, Scanned: 400, Open: 1},
+			},
+			contains: []string{"<b>4</b>hosts", "<b>400</b>ports scanned", "<b>1</b>open"},
+		},
+		{
+			name: "banners are escaped",
+			report: types.Report{Results: []types.Result{
+				{Host: "10.0.0.1", Port: 80, Status: true, Banner: `<script>alert("x")</script>`},
+			}},
+			contains:    []string{"&lt;script&gt;alert(&#34;x&#34;)&lt;/script&gt;"},
+			notContains: []string{`<script>alert("x")</script>`},
+		},
+		{
+			name: "self-contained",
+			report: types.Report{Results: []types.Result{
+				{Host: "10.0.0.1", Port: 80, Status: true},
+			}},
+			contains:    []string{"<style>", "<script>"},
+			notContains: []string{"<link", "src=", "http://", "https://"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := toHTML(tt.report)
+			if err != nil {
+				t.Fatalf("toHTML() error = %v", err)
+			}
+
+			for _, want := range tt.contains {
+				if !strings.Contains(output, want) {
+					t.Errorf("toHTML() missing %q", want)
+				}
+			}
+			for _, unwanted := range tt.notContains {
+				if strings.Contains(output, unwanted) {
+					t.Errorf("toHTML() contains %q", unwanted)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,225 @@
+package output
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"os"
+	"port-scanner/internal/config"
+	"port-scanner/internal/hostmatch"
+	"port-scanner/internal/types"
+	"slices"
+)
+
+const (
+	junitSuitesName     = "port-scanner"
+	failureUnexpected   = "unexpected-open"
+	failureMissing      = "expected-open"
+	failureMustBeClosed = "expected-closed"
+	junitTimeFormat     = "2006-01-02T15:04:05"
+	junitPolicySuite    = "policy"
+)
+
+var (
+	readExpectationsError    = errors.New("failed to read expectations file")
+	invalidExpectationsError = errors.New("invalid expectations file")
+)
+
+type Expectation struct {
+	Open   []int `yaml:"open"`
+	Closed []int `yaml:"closed"`
+}
+
+type Expectations struct {
+	Default Expectation            `yaml:"default"`
+	Hosts   map[string]Expectation `yaml:"hosts"`
+}
+
+type junitSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Name     string       `xml:"name,attr"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Time     string       `xml:"time,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name      string      `xml:"name,attr"`
+	Tests     int         `xml:"tests,attr"`
+	Failures  int         `xml:"failures,attr"`
+	Timestamp string      `xml:"timestamp,attr,omitempty"`
+	Cases     []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Failure   *junitFailure `xml:"failure"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+func LoadExpectations(path string) (Expectations, error) {
+	if path == "" {
+		return Expectations{}, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Expectations{}, fmt.Errorf("%w: %s", readExpectationsError, path)
+	}
+
+	var expectations Expectations
+	err = config.Decode(data, &expectations)
+	if err != nil {
+		return Expectations{}, fmt.Errorf("%w: %s: %v", invalidExpectationsError, path, err)
+	}
+
+	err = hostmatch.Validate(expectations.Hosts)
+	if err != nil {
+		return Expectations{}, fmt.Errorf("%w: %s: %v", invalidExpectationsError, path, err)
+	}
+
+	return expectations, nil
+}
+
+func (e Expectations) For(host string) Expectation {
+	return hostmatch.Lookup(e.Hosts, e.Default, host)
+}
+
+func toJUnit(report types.Report, cfg types.Config) (string, error) {
+	expectations, err := LoadExpectations(cfg.Expectations)
+	if err != nil {
+		return "", err
+	}
+
+	suites := junitSuites{
+		Name:   junitSuitesName,
+		Time:   fmt.Sprintf("%.3f", report.Metadata.End.Sub(report.Metadata.Start).Seconds()),
+		Suites: make([]junitSuite, 0),
+	}
+
+	hosts, open := openPortsByHost(report.Results)
+	for _, host := range hosts {
+		suite := junitSuite{Name: hostmatch.Name(host), Cases: junitCases(host, open[host], expectations.For(host))}
+		if !report.Metadata.Start.IsZero() {
+			suite.Timestamp = report.Metadata.Start.UTC().Format(junitTimeFormat)
+		}
+
+		suite.Tests = len(suite.Cases)
+		for _, c := range suite.Cases {
+			if c.Failure != nil {
+				suite.Failures++
+			}
+		}
+
+		suites.Tests += suite.Tests
+		suites.Failures += suite.Failures
+		suites.Suites = append(suites.Suites, suite)
+	}
+
+	if len(report.Violations) > 0 {
+		suite := junitSuite{Name: junitPolicySuite, Cases: policyCases(report.Violations)}
+		suite.Tests = len(suite.Cases)
+		suite.Failures = len(suite.Cases)
+		suites.Tests += suite.Tests
+		suites.Failures += suite.Failures
+		suites.Suites = append(suites.Suites, suite)
+	}
+
+	data, err := xml.MarshalIndent(suites, "", "  ")
+	if err != nil {
+		return "", writeFileError
+	}
+
+	return xml.Header + string(data) + "\n", nil
+}
+
+func openPortsByHost(results []types.Result) ([]string, map[string][]int) {
+	hosts := make([]string, 0)
+	open := make(map[string][]int)
+
+	for _, r := range results {
+		if _, ok := open[r.Host]; !ok {
+			hosts = append(hosts, r.Host)
+			open[r.Host] = []int{}
+		}
+		if r.Status {
+			open[r.Host] = append(open[r.Host], r.Port)
+		}
+	}
+
+	return hosts, open
+}
+
+func junitCases(host string, open []int, expectation Expectation) []junitCase {
+	className := hostmatch.Name(host)
+	cases := make([]junitCase, 0)
+
+	for _, port := range expectation.Open {
+		c := junitCase{ClassName: className, Name: fmt.Sprintf("port %d is open", port)}
+		if !slices.Contains(open, port) {
+			c.Failure = &junitFailure{
+				Message: fmt.Sprintf("expected port %d to be open", port),
+				Type:    failureMissing,
+				Text:    fmt.Sprintf("%s: port %d is closed or was not scanned", className, port),
+			}
+		}
+		cases = append(cases, c)
+	}
+
+	for _, port := range expectation.Closed {
+		if slices.Contains(expectation.Open, port) {
+			continue
+		}
+
+		c := junitCase{ClassName: className, Name: fmt.Sprintf("port %d is closed", port)}
+		if slices.Contains(open, port) {
+			c.Failure = &junitFailure{
+				Message: fmt.Sprintf("expected port %d to be closed", port),
+				Type:    failureMustBeClosed,
+				Text:    fmt.Sprintf("%s: port %d is open", className, port),
+			}
+		}
+		cases = append(cases, c)
+	}
+
+	for _, port := range open {
+		if slices.Contains(expectation.Open, port) || slices.Contains(expectation.Closed, port) {
+			continue
+		}
+
+		cases = append(cases, junitCase{
+			ClassName: className,
+			Name:      fmt.Sprintf("port %d is not expected", port),
+			Failure: &junitFailure{
+				Message: fmt.Sprintf("unexpected open port %d", port),
+				Type:    failureUnexpected,
+				Text:    fmt.Sprintf("%s: port %d is open but not listed in the expectations", className, port),
+			},
+		})
+	}
+
+	return cases
+}
+
+func policyCases(violations []types.Violation) []junitCase {
+	cases := make([]junitCase, 0, len(violations))
+	for _, v := range violations {
+		cases = append(cases, junitCase{
+			ClassName: junitPolicySuite + "." + hostmatch.Name(v.Host),
+			Name:      fmt.Sprintf("port %d %s", v.Port, v.Rule),
+			Failure: &junitFailure{
+				Message: v.Message,
+				Type:    v.Rule,
+				Text:    v.Message,
+			},
+		})
+	}
+	return cases
+}
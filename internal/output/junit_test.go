@@ -0,0 +1,164 @@
+package output
+
+import (
+	"encoding/xml"
+	"errors"
+	"os"
+	"path/filepath"
+	"port-scanner/internal/types"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testExpectations = `
+default:
+  open: [443]
+hosts:
+  10.0.0.0/24:
+    open: [22, 443]
+  10.0.0.5:
+    open: [80]
+    closed: [22]
+`
+
+func writeExpectations(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "expectations.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write expectations: %v", err)
+	}
+	return path
+}
+
+func TestLoadExpectations(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr error
+	}{
+		{"valid", testExpectations, nil},
+		{"empty", "", nil},
+		{"unknown key", "default:\n  filtered: [80]\n", invalidExpectationsError},
+		{"invalid port", "default:\n  open: [https]\n", invalidExpectationsError},
+		{"invalid cidr", "hosts:\n  10.0.0.0/99:\n    open: [22]\n", invalidExpectationsError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadExpectations(writeExpectations(t, tt.content))
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("LoadExpectations() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if _, err := LoadExpectations(filepath.Join(t.TempDir(), "missing.yaml")); !errors.Is(err, readExpectationsError) {
+		t.Errorf("LoadExpectations() error = %v, want %v", err, readExpectationsError)
+	}
+}
+
+func TestExpectationsFor(t *testing.T) {
+	expectations, err := LoadExpectations(writeExpectations(t, testExpectations))
+	if err != nil {
+		t.Fatalf("LoadExpectations() error = %v", err)
+	}
+
+	tests := []struct {
+		host     string
+		expected Expectation
+	}{
+		{"10.0.0.5", Expectation{Open: []int{80}, Closed: []int{22}}},
+		{"10.0.0.6", Expectation{Open: []int{22, 443}}},
+		{"192.168.1.1", Expectation{Open: []int{443}}},
+		{"example.com", Expectation{Open: []int{443}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			if got := expectations.For(tt.host); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("For(%q) = %+v, want %+v", tt.host, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestToJUnit(t *testing.T) {
+	results := []types.Result{
+		{Host: "10.0.0.5", Port: 22, Status: true},
+		{Host: "10.0.0.5", Port: 80, Status: true},
+		{Host: "10.0.0.6", Port: 22, Status: true},
+		{Host: "10.0.0.6", Port: 443, Status: false},
+		{Host: "192.168.1.1", Port: 443, Status: true},
+		{Host: "192.168.1.1", Port: 8080, Status: true},
+	}
+
+	output, err := toJUnit(types.Report{Results: results}, types.Config{Expectations: writeExpectations(t, testExpectations)})
+	if err != nil {
+		t.Fatalf("toJUnit() error = %v", err)
+	}
+
+	if !strings.HasPrefix(output, xml.Header+"<testsuites") {
+		t.Errorf("toJUnit() missing xml header:\n%s", output)
+	}
+
+	var suites junitSuites
+	if err := xml.Unmarshal([]byte(output), &suites); err != nil {
+		t.Fatalf("toJUnit() produced invalid XML: %v", err)
+	}
+
+	if suites.Tests != 6 || suites.Failures != 3 {
+		t.Errorf("testsuites tests = %d failures = %d, want 6 and 3", suites.Tests, suites.Failures)
+	}
+
+	expected := map[string]map[string]string{
+		"10.0.0.5": {
+			"port 80 is open":   "",
+			"port 22 is closed": failureMustBeClosed,
+		},
+		"10.0.0.6": {
+			"port 22 is open":  "",
+			"port 443 is open": failureMissing,
+		},
+		"192.168.1.1": {
+			"port 443 is open":          "",
+			"port 8080 is not expected": failureUnexpected,
+		},
+	}
+
+	if len(suites.Suites) != len(expected) {
+		t.Fatalf("toJUnit() wrote %d suites, want %d", len(suites.Suites), len(expected))
+	}
+
+	for _, suite := range suites.Suites {
+		got := make(map[string]string)
+		for _, c := range suite.Cases {
+			if c.ClassName != suite.Name {
+				t.Errorf("testcase %q classname = %q, want %q", c.Name, c.ClassName, suite.Name)
+			}
+			got[c.Name] = ""
+			if c.Failure != nil {
+				got[c.Name] = c.Failure.Type
+			}
+		}
+		if !reflect.DeepEqual(got, expected[suite.Name]) {
+			t.Errorf("suite %s = %v, want %v", suite.Name, got, expected[suite.Name])
+		}
+	}
+}
+
+func TestToJUnitWithoutExpectations(t *testing.T) {
+	output, err := toJUnit(types.Report{Results: testResults}, types.Config{})
+	if err != nil {
+		t.Fatalf("toJUnit() error = %v", err)
+	}
+
+	var suites junitSuites
+	if err := xml.Unmarshal([]byte(output), &suites); err != nil {
+		t.Fatalf("toJUnit() produced invalid XML: %v", err)
+	}
+
+	if suites.Tests != 2 || suites.Failures != 2 {
+		t.Errorf("testsuites tests = %d failures = %d, want every open port to fail", suites.Tests, suites.Failures)
+	}
+}
@@ -0,0 +1,247 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"port-scanner/internal/types"
+	"sync"
+	"time"
+)
+
+const (
+	recordHeader    = "header"
+	recordFooter    = "footer"
+	recordViolation = "violation"
+)
+
+type ndjsonHeader struct {
+	Record  string    `json:"record"`
+	Version string    `json:"version,omitempty"`
+	Args    []string  `json:"args,omitempty"`
+	Start   time.Time `json:"start"`
+	Total   int       `json:"total"`
+}
+
+type ndjsonFooter struct {
+	Record   string    `json:"record"`
+	End      time.Time `json:"end"`
+	Duration float64   `json:"duration_seconds"`
+	Results  int       `json:"results"`
+	Open     int       `json:"open"`
+}
+
+type ndjsonViolation struct {
+	Record string `json:"record"`
+	types.Violation
+}
+
+type Stream struct {
+	mu           sync.Mutex
+	metadata     types.Metadata
+	meta         bool
+	filter       Filter
+	destinations []Destination
+	writers      []io.Writer
+	files        []*os.File
+	results      int
+	open         int
+	finished     bool
+	err          error
+}
+
+func toNDJSON(report types.Report, meta bool) (string, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+
+	open := 0
+	for _, r := range report.Results {
+		if r.Status {
+			open++
+		}
+	}
+
+	records := make([]any, 0, len(report.Results)+len(report.Violations)+2)
+	if meta {
+		records = append(records, newNDJSONHeader(report.Metadata, len(report.Results)))
+	}
+	for _, r := range report.Results {
+		records = append(records, r)
+	}
+	for _, v := range report.Violations {
+		records = append(records, ndjsonViolation{Record: recordViolation, Violation: v})
+	}
+	if meta {
+		records = append(records, newNDJSONFooter(report.Metadata, len(report.Results), open))
+	}
+
+	for _, record := range records {
+		err := encoder.Encode(record)
+		if err != nil {
+			return "", writeFileError
+		}
+	}
+
+	return buf.String(), nil
+}
+
+func newNDJSONHeader(metadata types.Metadata, total int) ndjsonHeader {
+	return ndjsonHeader{
+		Record:  recordHeader,
+		Version: metadata.Version,
+		Args:    metadata.Args,
+		Start:   metadata.Start,
+		Total:   total,
+	}
+}
+
+func newNDJSONFooter(metadata types.Metadata, results, open int) ndjsonFooter {
+	return ndjsonFooter{
+		Record:   recordFooter,
+		End:      metadata.End,
+		Duration: metadata.End.Sub(metadata.Start).Seconds(),
+		Results:  results,
+		Open:     open,
+	}
+}
+
+func OpenStream(cfg types.Config, metadata types.Metadata) (*Stream, error) {
+	destinations, err := Destinations(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	filter, err := NewFilter(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Stream{metadata: metadata, meta: cfg.NdjsonMeta, filter: filter}
+	for _, d := range destinations {
+		if d.Format != FormatNdjson {
+			continue
+		}
+
+		if d.Path == stdoutPath {
+			s.writers = append(s.writers, os.Stdout)
+			s.destinations = append(s.destinations, d)
+			continue
+		}
+
+		file, err := createStreamFile(d.outputPath())
+		if err != nil {
+			_ = s.Close()
+			return nil, err
+		}
+		s.files = append(s.files, file)
+		s.writers = append(s.writers, file)
+		s.destinations = append(s.destinations, d)
+	}
+
+	return s, nil
+}
+
+func createStreamFile(path string) (*os.File, error) {
+	err := os.MkdirAll(filepath.Dir(path), directoryPermission)
+	if err != nil {
+		return nil, writeFileError
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, filePermission)
+	if err != nil {
+		return nil, writeFileError
+	}
+
+	_, _ = fmt.Fprintln(os.Stderr, path)
+	return file, nil
+}
+
+func (s *Stream) Destinations() []Destination {
+	return s.destinations
+}
+
+func (s *Stream) Start(total int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.metadata.Start.IsZero() {
+		s.metadata.Start = time.Now()
+	}
+	if s.meta {
+		s.write(newNDJSONHeader(s.metadata, total))
+	}
+}
+
+func (s *Stream) Increment(result types.Result) {
+	if !s.filter(result) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.results++
+	if result.Status {
+		s.open++
+	}
+	s.write(result)
+}
+
+func (s *Stream) Finish() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.metadata.End = time.Now()
+	s.finished = true
+}
+
+func (s *Stream) Violations(violations []types.Violation) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, v := range violations {
+		s.write(ndjsonViolation{Record: recordViolation, Violation: v})
+	}
+}
+
+func (s *Stream) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.finished && s.meta {
+		s.write(newNDJSONFooter(s.metadata, s.results, s.open))
+	}
+	s.finished = false
+
+	for _, file := range s.files {
+		err := file.Close()
+		if err != nil && s.err == nil {
+			s.err = writeFileError
+		}
+	}
+	s.files = nil
+	return s.err
+}
+
+func (s *Stream) write(record any) {
+	if len(s.writers) == 0 {
+		return
+	}
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		s.err = writeFileError
+		return
+	}
+	data = append(data, '\n')
+
+	for _, w := range s.writers {
+		_, err = w.Write(data)
+		if err != nil && s.err == nil {
+			s.err = writeFileError
+		}
+	}
+}
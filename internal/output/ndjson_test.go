@@ -0,0 +1,181 @@
+package output
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"port-scanner/internal/types"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestToNDJSON(t *testing.T) {
+	start := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	report := types.Report{
+		Results: testResults,
+		Metadata: types.Metadata{
+			Version: "1.0.0",
+			Args:    []string{"port-scanner", "-f", "ndjson"},
+			Start:   start,
+			End:     start.Add(2 * time.Second),
+		},
+	}
+
+	tests := []struct {
+		name     string
+		meta     bool
+		expected []string
+	}{
+		{
+			name: "results only",
+			meta: false,
+			expected: []string{
+				`{"port":80,"status":true}`,
+				`{"port":443,"status":false}`,
+				`{"port":8080,"status":true}`,
+			},
+		},
+		{
+			name: "with header and footer",
+			meta: true,
+			expected: []string{
+				`{"record":"header","version":"1.0.0","args":["port-scanner","-f","ndjson"],"start":"2026-01-02T03:04:05Z","total":3}`,
+				`{"port":80,"status":true}`,
+				`{"port":443,"status":false}`,
+				`{"port":8080,"status":true}`,
+				`{"record":"footer","end":"2026-01-02T03:04:07Z","duration_seconds":2,"results":3,"open":2}`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := toNDJSON(report, tt.meta)
+			if err != nil {
+				t.Fatalf("toNDJSON() error = %v", err)
+			}
+
+			lines := strings.Split(strings.TrimSuffix(output, "\n"), "\n")
+			if !reflect.DeepEqual(lines, tt.expected) {
+				t.Errorf("toNDJSON() =\n%s\nwant\n%s", strings.Join(lines, "\n"), strings.Join(tt.expected, "\n"))
+			}
+
+			parsed, err := Parse([]byte(output), FormatNdjson)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if !reflect.DeepEqual(parsed, testResults) {
+				t.Errorf("Parse() = %+v, want %+v", parsed, testResults)
+			}
+		})
+	}
+}
+
+func TestStream(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      types.Config
+		records  []string
+		streamed int
+	}{
+		{
+			name:     "results as they arrive",
+			cfg:      types.Config{Format: "ndjson"},
+			records:  []string{"", "", ""},
+			streamed: 1,
+		},
+		{
+			name:     "filtered results with metadata",
+			cfg:      types.Config{Format: "ndjson", OpenOnly: true, NdjsonMeta: true},
+			records:  []string{"header", "", "", "footer"},
+			streamed: 1,
+		},
+		{
+			name:     "other formats are not streamed",
+			cfg:      types.Config{Format: "json", Outputs: []string{"csv:results.csv"}},
+			streamed: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "results.ndjson")
+			tt.cfg.Output = path
+
+			stream, err := OpenStream(tt.cfg, types.Metadata{Version: "1.0.0"})
+			if err != nil {
+				t.Fatalf("OpenStream() error = %v", err)
+			}
+
+			if len(stream.Destinations()) != tt.streamed {
+				t.Fatalf("Destinations() = %+v, want %d", stream.Destinations(), tt.streamed)
+			}
+			if tt.streamed == 0 {
+				if err := stream.Close(); err != nil {
+					t.Errorf("Close() error = %v", err)
+				}
+				return
+			}
+
+			stream.Start(len(testResults))
+			stream.Increment(testResults[0])
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("ReadFile() error = %v", err)
+			}
+			if !strings.Contains(string(data), `{"port":80,"status":true}`) {
+				t.Errorf("result was not written before the scan finished: %q", data)
+			}
+
+			for _, r := range testResults[1:] {
+				stream.Increment(r)
+			}
+			stream.Finish()
+			if err := stream.Close(); err != nil {
+				t.Fatalf("Close() error = %v", err)
+			}
+
+			data, err = os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("ReadFile() error = %v", err)
+			}
+
+			lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+			got := make([]string, 0, len(lines))
+			for _, line := range lines {
+				var record struct {
+					Record string `json:"record"`
+				}
+				if err := json.Unmarshal([]byte(line), &record); err != nil {
+					t.Fatalf("invalid record %q: %v", line, err)
+				}
+				got = append(got, record.Record)
+			}
+			if !reflect.DeepEqual(got, tt.records) {
+				t.Errorf("records = %q, want %q", got, tt.records)
+			}
+		})
+	}
+}
+
+func TestExportSkipsStreamed(t *testing.T) {
+	dir := t.TempDir()
+	streamed := filepath.Join(dir, "streamed.ndjson")
+	exported := filepath.Join(dir, "exported.csv")
+
+	cfg := types.Config{Format: "ndjson", Output: streamed, Outputs: []string{"csv:" + exported}}
+	err := Export(types.Report{Results: testResults}, cfg, Destination{Format: FormatNdjson, Path: streamed})
+	if err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	if _, err := os.Stat(streamed); !os.IsNotExist(err) {
+		t.Errorf("Export() wrote streamed destination %s", streamed)
+	}
+	if _, err := os.Stat(exported); err != nil {
+		t.Errorf("Export() did not write %s: %v", exported, err)
+	}
+}
@@ -7,15 +7,20 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"port-scanner/internal/hostmatch"
 	"port-scanner/internal/types"
 	"port-scanner/internal/utils"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 )
 
 const (
+	headerHost          = "Host"
 	headerPort          = "Port"
 	headerStatus        = "Status"
+	statusSkipped       = "skipped"
 	dateFormat          = "2006-01-02_15:04:05"
 	outputDirectory     = "/output"
 	directoryPermission = 0755
@@ -26,37 +31,61 @@ var (
 	writeFileError = errors.New("failed to write file")
 )
 
-func Export(results []types.Result, cfg types.Config) error {
-	format, err := ParseFormat(cfg.Format)
+func Export(report types.Report, cfg types.Config, streamed ...Destination) error {
+	destinations, err := Destinations(cfg)
 	if err != nil {
-		format = FormatTxt
+		return err
 	}
 
-	output, err := formatResults(results, format)
+	filter, err := NewFilter(cfg)
 	if err != nil {
 		return err
 	}
 
-	outputPath := generateOutputPath(cfg.Output, format.Extension())
-	fmt.Println(outputPath)
-	err = writeToFile(outputPath, output)
-	if err != nil {
-		return err
+	report.Results = applyFilter(report.Results, filter)
+	for _, destination := range destinations {
+		if slices.Contains(streamed, destination) {
+			continue
+		}
+
+		err = destination.write(report, cfg)
+		if err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
-func formatResults(results []types.Result, format Format) (string, error) {
+func Render(report types.Report, format Format, cfg types.Config) (string, error) {
+	return formatReport(report, format, cfg)
+}
+
+func formatReport(report types.Report, format Format, cfg types.Config) (string, error) {
 	switch format {
-	case FormatCsv:
-		return toCSV(results)
 	case FormatJson:
-		return toJSON(results)
-	case FormatTxt:
-		return toTXT(results), nil
+		if cfg.JsonLegacy {
+			return toJSON(report.Results)
+		}
+		return toJSONEnvelope(report)
+	case FormatNdjson:
+		return toNDJSON(report, cfg.NdjsonMeta)
+	case FormatXml:
+		return toXML(report)
+	case FormatHtml:
+		return toHTML(report)
+	case FormatJunit:
+		return toJUnit(report, cfg)
+	case FormatProm:
+		return toProm(report), nil
+	case FormatCsv:
+		return toCSV(report.Results, report.Violations...)
+	case FormatGrep:
+		return toGrep(report.Results, report.Violations...), nil
+	case FormatMarkdown:
+		return toMarkdown(report), nil
 	default:
-		return toTXT(results), nil
+		return toTXT(report.Results, report.Violations...), nil
 	}
 }
 
@@ -68,20 +97,32 @@ func toJSON(results []types.Result) (string, error) {
 	return string(data), nil
 }
 
-func toCSV(results []types.Result) (string, error) {
+func toCSV(results []types.Result, violations ...types.Violation) (string, error) {
 	var sb strings.Builder
 	writer := csv.NewWriter(&sb)
 
-	err := writer.Write([]string{headerPort, headerStatus})
+	header := []string{headerHost, headerPort, headerStatus}
+	if len(violations) > 0 {
+		header = append(header, headerPolicy)
+	}
+
+	err := writer.Write(header)
 	if err != nil {
 		return "", writeFileError
 	}
 
+	index := indexViolations(violations)
 	for _, r := range results {
-		err = writer.Write([]string{
+		record := []string{
+			r.Host,
 			fmt.Sprintf("%d", r.Port),
-			fmt.Sprintf("%t", r.Status),
-		})
+			formatStatus(r),
+		}
+		if len(violations) > 0 {
+			record = append(record, index.rules(r.Host, r.Port))
+		}
+
+		err = writer.Write(record)
 		if err != nil {
 			return "", writeFileError
 		}
@@ -97,17 +138,100 @@ func toCSV(results []types.Result) (string, error) {
 	return strings.TrimSuffix(content, "\n"), nil
 }
 
-func toTXT(results []types.Result) string {
+func toTXT(results []types.Result, violations ...types.Violation) string {
+	width := len(headerHost)
+	statusWidth := len(headerStatus)
+	for _, result := range results {
+		width = max(width, len(hostmatch.Name(result.Host)))
+		statusWidth = max(statusWidth, len(formatStatus(result)))
+	}
+
 	var sb strings.Builder
-	sb.WriteString(fmt.Sprintf("%-6s %-6s\n", headerPort, headerStatus))
+	if len(violations) == 0 {
+		sb.WriteString(fmt.Sprintf("%-*s %-6s %-*s\n", width, headerHost, headerPort, statusWidth, headerStatus))
+		for _, result := range results {
+			sb.WriteString(fmt.Sprintf("%-*s %-6d %-*s\n", width, hostmatch.Name(result.Host), result.Port, statusWidth, formatStatus(result)))
+		}
+		return sb.String()
+	}
 
+	index := indexViolations(violations)
+	sb.WriteString(fmt.Sprintf("%-*s %-6s %-*s %s\n", width, headerHost, headerPort, statusWidth, headerStatus, headerPolicy))
 	for _, result := range results {
-		sb.WriteString(fmt.Sprintf("%-6d %-6t\n", result.Port, result.Status))
+		sb.WriteString(strings.TrimRight(fmt.Sprintf("%-*s %-6d %-*s %s",
+			width, hostmatch.Name(result.Host), result.Port, statusWidth, formatStatus(result), index.rules(result.Host, result.Port)), " ") + "\n")
+	}
+	return sb.String()
+}
+
+func formatStatus(r types.Result) string {
+	if r.Skipped {
+		return statusSkipped
+	}
+	return strconv.FormatBool(r.Status)
+}
+
+func toMarkdown(report types.Report) string {
+	hosts := make([]string, 0)
+	open := make(map[string][]types.Result)
+	openCount := 0
+	skippedCount := 0
+
+	for _, r := range report.Results {
+		if _, ok := open[r.Host]; !ok {
+			hosts = append(hosts, r.Host)
+			open[r.Host] = []types.Result{}
+		}
+		if r.Status {
+			open[r.Host] = append(open[r.Host], r)
+			openCount++
+		}
+		if r.Skipped {
+			skippedCount++
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString("# Port scan results\n\n")
+	scannedHosts, scannedPorts := scannedCounts(report)
+	sb.WriteString(fmt.Sprintf("- Hosts: %d\n- Ports scanned: %d\n- Open: %d\n", scannedHosts, scannedPorts, openCount))
+	if skippedCount > 0 {
+		sb.WriteString(fmt.Sprintf("- Skipped after the host timeout: %d\n", skippedCount))
+	}
+	if len(report.Violations) > 0 {
+		sb.WriteString(fmt.Sprintf("- Policy violations: %d\n", len(report.Violations)))
+	}
+
+	for _, host := range hosts {
+		sb.WriteString(fmt.Sprintf("\n## %s\n\n", mdEscape(hostmatch.Name(host))))
+		if len(open[host]) == 0 {
+			sb.WriteString("_No open ports_\n")
+			continue
+		}
+
+		sb.WriteString("| Port | Service | Banner |\n")
+		sb.WriteString("| :--- | :------ | :----- |\n")
+		for _, r := range open[host] {
+			sb.WriteString(fmt.Sprintf("| %d | %s | %s |\n", r.Port, mdEscape(r.Service), mdEscape(r.Banner)))
+		}
+	}
+
+	if len(report.Violations) > 0 {
+		sb.WriteString("\n## Policy violations\n\n")
+		sb.WriteString("| Host | Port | Rule | Message |\n")
+		sb.WriteString("| :--- | :--- | :--- | :------ |\n")
+		for _, v := range report.Violations {
+			sb.WriteString(fmt.Sprintf("| %s | %d | %s | %s |\n", mdEscape(hostmatch.Name(v.Host)), v.Port, v.Rule, mdEscape(v.Message)))
+		}
 	}
 
 	return sb.String()
 }
 
+func mdEscape(s string) string {
+	return strings.NewReplacer("|", "\\|", "`", "\\`", "*", "\\*").Replace(s)
+}
+
 func generateOutputPath(output, extension string) string {
 	if utils.IsDockerized() {
 		return generateDockerOutputPath(output, extension)
@@ -169,3 +293,15 @@ func writeToFile(filePath, content string) error {
 
 	return nil
 }
+
+func scannedCounts(report types.Report) (int, int) {
+	if report.Summary.Scanned > 0 {
+		return report.Summary.Hosts, report.Summary.Scanned
+	}
+
+	hosts := make(map[string]bool)
+	for _, r := range report.Results {
+		hosts[r.Host] = true
+	}
+	return len(hosts), len(report.Results)
+}
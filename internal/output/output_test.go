@@ -2,9 +2,11 @@ package output
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
 	"port-scanner/internal/types"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
@@ -53,7 +55,7 @@ func TestExport(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := Export(tt.results, tt.config)
+			err := Export(types.Report{Results: tt.results}, tt.config)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Export() error = %v, wantErr %v", err, tt.wantErr)
 			}
@@ -61,7 +63,52 @@ func TestExport(t *testing.T) {
 	}
 }
 
-func TestFormatResults(t *testing.T) {
+func TestExportFilter(t *testing.T) {
+	tempDir := t.TempDir()
+
+	tests := []struct {
+		name     string
+		config   types.Config
+		expected []int
+		wantErr  bool
+	}{
+		{"txt open only", types.Config{Format: "txt", OpenOnly: true}, []int{80, 8080}, false},
+		{"csv open only", types.Config{Format: "csv", OpenOnly: true}, []int{80, 8080}, false},
+		{"json open only", types.Config{Format: "json", OpenOnly: true}, []int{80, 8080}, false},
+		{"txt filter", types.Config{Format: "txt", Filter: "port < 1024"}, []int{80, 443}, false},
+		{"csv filter", types.Config{Format: "csv", Filter: "port < 1024"}, []int{80, 443}, false},
+		{"json filter", types.Config{Format: "json", Filter: "port < 1024"}, []int{80, 443}, false},
+		{"invalid filter", types.Config{Format: "txt", Filter: "port <"}, nil, true},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.config.Output = filepath.Join(tempDir, fmt.Sprintf("filtered_%d.%s", i, tt.config.Format))
+			err := Export(types.Report{Results: testResults}, tt.config)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Export() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			results, err := Load(tt.config.Output)
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+
+			got := make([]int, 0)
+			for _, r := range results {
+				got = append(got, r.Port)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("exported ports = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
 	tests := []struct {
 		name    string
 		results []types.Result
@@ -86,6 +133,18 @@ func TestFormatResults(t *testing.T) {
 			format:  FormatTxt,
 			wantErr: false,
 		},
+		{
+			name:    "format as markdown",
+			results: testResults,
+			format:  FormatMarkdown,
+			wantErr: false,
+		},
+		{
+			name:    "format as grep",
+			results: testResults,
+			format:  FormatGrep,
+			wantErr: false,
+		},
 		{
 			name:    "format with empty results",
 			results: []types.Result{},
@@ -102,13 +161,13 @@ func TestFormatResults(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			output, err := formatResults(tt.results, tt.format)
+			output, err := Render(types.Report{Results: tt.results}, tt.format, types.Config{})
 			if (err != nil) != tt.wantErr {
-				t.Errorf("formatResults() error = %v, wantErr %v", err, tt.wantErr)
+				t.Errorf("Render() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
 			if !tt.wantErr && output == "" {
-				t.Errorf("formatResults() returned empty output")
+				t.Errorf("Render() returned empty output")
 			}
 		})
 	}
@@ -231,6 +290,74 @@ func TestToTXT(t *testing.T) {
 	}
 }
 
+func TestToMarkdown(t *testing.T) {
+	tests := []struct {
+		name     string
+		results  []types.Result
+		summary  types.Summary
+		expected string
+	}{
+		{
+			name:     "empty results",
+			results:  []types.Result{},
+			expected: "# Port scan results\n\n- Hosts: 0\n- Ports scanned: 0\n- Open: 0\n",
+		},
+		{
+			name: "open ports per host",
+			results: []types.Result{
+				{Host: "10.0.0.1", Port: 22, Status: true, Service: "ssh", Banner: "SSH-2.0-OpenSSH_9.6"},
+				{Host: "10.0.0.1", Port: 23, Status: false},
+				{Host: "10.0.0.2", Port: 80, Status: false},
+				{Host: "10.0.0.1", Port: 443, Status: true, Service: "https"},
+			},
+			expected: "# Port scan results\n\n" +
+				"- Hosts: 2\n- Ports scanned: 4\n- Open: 2\n" +
+				"\n## 10.0.0.1\n\n" +
+				"| Port | Service | Banner |\n" +
+				"| :--- | :------ | :----- |\n" +
+				"| 22 | ssh | SSH-2.0-OpenSSH_9.6 |\n" +
+				"| 443 | https |  |\n" +
+				"\n## 10.0.0.2\n\n" +
+				"_No open ports_\n",
+		},
+		{
+			name:    "results without host",
+			results: testResults,
+			expected: "# Port scan results\n\n" +
+				"- Hosts: 1\n- Ports scanned: 3\n- Open: 2\n" +
+				"\n## -\n\n" +
+				"| Port | Service | Banner |\n" +
+				"| :--- | :------ | :----- |\n" +
+				"| 80 |  |  |\n" +
+				"| 8080 |  |  |\n",
+		},
+		{
+			name:     "counts from the summary after filtering",
+			results:  []types.Result{{Host: "10.0.0.1", Port: 22, Status: true}},
+			summary:  types.Summary{Hosts: 4, Ports: 100, Scanned: 400, Open: 1},
+			expected: "# Port scan results\n\n- Hosts: 4\n- Ports scanned: 400\n- Open: 1\n\n## 10.0.0.1\n\n| Port | Service | Banner |\n| :--- | :------ | :----- |\n| 22 |  |  |\n",
+		},
+		{
+			name:    "markdown characters are escaped",
+			results: []types.Result{{Host: "10.0.0.1", Port: 80, Status: true, Service: "http_alt", Banner: "a|b *c* `d`"}},
+			expected: "# Port scan results\n\n" +
+				"- Hosts: 1\n- Ports scanned: 1\n- Open: 1\n" +
+				"\n## 10.0.0.1\n\n" +
+				"| Port | Service | Banner |\n" +
+				"| :--- | :------ | :----- |\n" +
+				"| 80 | http_alt | a\\|b \\*c\\* \\`d\\` |\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := toMarkdown(types.Report{Results: tt.results, Summary: tt.summary}); got != tt.expected {
+				t.Errorf("toMarkdown() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
 func TestGenerateOutputPath(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -0,0 +1,287 @@
+package output
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"port-scanner/internal/hostmatch"
+	"port-scanner/internal/types"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	readFileError    = errors.New("failed to read file")
+	parseResultError = errors.New("failed to parse results")
+)
+
+func Load(path string) ([]types.Result, error) {
+	report, err := LoadReport(path)
+	if err != nil {
+		return nil, err
+	}
+	return report.Results, nil
+}
+
+func LoadReport(path string) (types.Report, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return types.Report{}, fmt.Errorf("%w: %s", readFileError, path)
+	}
+
+	format, err := ParseFormat(strings.TrimPrefix(filepath.Ext(path), "."))
+	if err != nil {
+		format = detectFormat(data)
+	}
+
+	report, err := ParseReport(data, format)
+	if err != nil {
+		return types.Report{}, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return report, nil
+}
+
+func Parse(data []byte, format Format) ([]types.Result, error) {
+	report, err := ParseReport(data, format)
+	if err != nil {
+		return nil, err
+	}
+	return report.Results, nil
+}
+
+func ParseReport(data []byte, format Format) (types.Report, error) {
+	switch format {
+	case FormatCsv:
+		return resultsReport(fromCSV(data))
+	case FormatJson:
+		return fromJSON(data)
+	case FormatNdjson:
+		return fromNDJSON(data)
+	case FormatTxt:
+		return resultsReport(fromTXT(data))
+	case FormatXml:
+		return fromXML(data)
+	default:
+		return types.Report{}, fmt.Errorf("%w: unsupported format %q", parseResultError, format)
+	}
+}
+
+func resultsReport(results []types.Result, err error) (types.Report, error) {
+	if err != nil {
+		return types.Report{}, err
+	}
+	return types.Report{Results: results}, nil
+}
+
+func detectFormat(data []byte) Format {
+	trimmed := bytes.TrimSpace(data)
+	if bytes.HasPrefix(trimmed, []byte("<")) {
+		return FormatXml
+	}
+	if bytes.HasPrefix(trimmed, []byte("[")) {
+		return FormatJson
+	}
+	if bytes.HasPrefix(trimmed, []byte("{")) {
+		if json.Valid(trimmed) && bytes.Contains(trimmed, []byte(`"schema_version"`)) {
+			return FormatJson
+		}
+		return FormatNdjson
+	}
+
+	header, _, _ := bytes.Cut(trimmed, []byte("\n"))
+	if bytes.Contains(header, []byte(",")) {
+		return FormatCsv
+	}
+
+	return FormatTxt
+}
+
+func fromJSON(data []byte) (types.Report, error) {
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		var e envelope
+		err := json.Unmarshal(data, &e)
+		if err != nil {
+			return types.Report{}, fmt.Errorf("%w: %v", parseResultError, err)
+		}
+		if e.SchemaVersion > jsonSchemaVersion {
+			return types.Report{}, fmt.Errorf("%w: unsupported schema version %d", parseResultError, e.SchemaVersion)
+		}
+		return types.Report{
+			Results:  e.Results,
+			Metadata: types.Metadata{Start: e.Scan.Start, End: e.Scan.End},
+		}, nil
+	}
+
+	var results []types.Result
+	err := json.Unmarshal(data, &results)
+	if err != nil {
+		return types.Report{}, fmt.Errorf("%w: %v", parseResultError, err)
+	}
+	return types.Report{Results: results}, nil
+}
+
+func fromNDJSON(data []byte) (types.Report, error) {
+	report := types.Report{Results: make([]types.Result, 0)}
+	for i, line := range bytes.Split(data, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+
+		var record struct {
+			Record string    `json:"record"`
+			Start  time.Time `json:"start"`
+			End    time.Time `json:"end"`
+			types.Result
+		}
+		err := json.Unmarshal(line, &record)
+		if err != nil {
+			return types.Report{}, fmt.Errorf("%w: line %d: %v", parseResultError, i+1, err)
+		}
+
+		switch record.Record {
+		case "":
+			report.Results = append(report.Results, record.Result)
+		case recordHeader:
+			report.Metadata.Start = record.Start
+		case recordFooter:
+			report.Metadata.End = record.End
+		}
+	}
+	return report, nil
+}
+
+func fromXML(data []byte) (types.Report, error) {
+	var run xmlRun
+	err := xml.Unmarshal(data, &run)
+	if err != nil {
+		return types.Report{}, fmt.Errorf("%w: %v", parseResultError, err)
+	}
+
+	results := make([]types.Result, 0)
+	for _, h := range run.Hosts {
+		host, address := xmlHostName(h)
+		for _, p := range h.Ports.Ports {
+			if p.Protocol != xmlProtocol {
+				continue
+			}
+
+			result := types.Result{
+				Host:    host,
+				Address: address,
+				Port:    p.PortID,
+				Status:  p.State.State == xmlStateOpen,
+				Skipped: p.State.State == xmlStateFiltered,
+			}
+			if p.Service != nil {
+				result.Service = p.Service.Name
+			}
+			for _, script := range p.Scripts {
+				if script.ID == xmlBannerScript {
+					result.Banner = script.Output
+				}
+			}
+			results = append(results, result)
+		}
+	}
+	report := types.Report{Results: results}
+	if run.Start != 0 {
+		report.Metadata.Start = time.Unix(run.Start, 0)
+	}
+	if run.RunStats.Finished.Time != 0 {
+		report.Metadata.End = time.Unix(run.RunStats.Finished.Time, 0)
+	}
+	return report, nil
+}
+
+func xmlHostName(h xmlHost) (string, string) {
+	address := ""
+	if len(h.Addresses) > 0 {
+		address = h.Addresses[0].Addr
+	}
+
+	if h.Hostnames != nil {
+		for _, name := range h.Hostnames.Hostnames {
+			if name.Type == xmlHostnameType {
+				return name.Name, address
+			}
+		}
+	}
+	return address, ""
+}
+
+func fromCSV(data []byte) ([]types.Result, error) {
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", parseResultError, err)
+	}
+	return fromRecords(records)
+}
+
+func fromTXT(data []byte) ([]types.Result, error) {
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	records := make([][]string, 0, len(lines))
+	for _, line := range lines {
+		if strings.TrimSpace(line) != "" {
+			records = append(records, strings.Fields(line))
+		}
+	}
+	return fromRecords(records)
+}
+
+func fromRecords(records [][]string) ([]types.Result, error) {
+	if len(records) == 0 {
+		return nil, fmt.Errorf("%w: missing header", parseResultError)
+	}
+
+	columns := make(map[string]int)
+	for i, name := range records[0] {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	portColumn, ok := columns[strings.ToLower(headerPort)]
+	if !ok {
+		return nil, fmt.Errorf("%w: missing %q column", parseResultError, headerPort)
+	}
+
+	statusColumn, ok := columns[strings.ToLower(headerStatus)]
+	if !ok {
+		return nil, fmt.Errorf("%w: missing %q column", parseResultError, headerStatus)
+	}
+
+	hostColumn, hasHost := columns[strings.ToLower(headerHost)]
+
+	results := make([]types.Result, 0, len(records)-1)
+	for i, record := range records[1:] {
+		if portColumn >= len(record) || statusColumn >= len(record) {
+			return nil, fmt.Errorf("%w: line %d: missing columns", parseResultError, i+2)
+		}
+
+		port, err := strconv.Atoi(strings.TrimSpace(record[portColumn]))
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: invalid port %q", parseResultError, i+2, record[portColumn])
+		}
+
+		result := types.Result{Port: port}
+		value := strings.TrimSpace(record[statusColumn])
+		if value == statusSkipped {
+			result.Skipped = true
+		} else if result.Status, err = strconv.ParseBool(value); err != nil {
+			return nil, fmt.Errorf("%w: line %d: invalid status %q", parseResultError, i+2, record[statusColumn])
+		}
+
+		if hasHost && hostColumn < len(record) && record[hostColumn] != hostmatch.Unknown {
+			result.Host = strings.TrimSpace(record[hostColumn])
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
@@ -0,0 +1,252 @@
+package output
+
+import (
+	"os"
+	"path/filepath"
+	"port-scanner/internal/types"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	formats := []Format{FormatCsv, FormatJson, FormatTxt}
+
+	for _, format := range formats {
+		t.Run(string(format), func(t *testing.T) {
+			content, err := formatReport(types.Report{Results: testResults}, format, types.Config{})
+			if err != nil {
+				t.Fatalf("formatReport() unexpected error: %v", err)
+			}
+
+			got, err := Parse([]byte(content), format)
+			if err != nil {
+				t.Fatalf("Parse() unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(got, testResults) {
+				t.Errorf("Parse() = %+v, want %+v", got, testResults)
+			}
+		})
+	}
+}
+
+func TestParseWithHosts(t *testing.T) {
+	results := []types.Result{
+		{Host: "10.0.0.1", Port: 22, Status: true},
+		{Host: "example.com", Port: 443, Status: false},
+	}
+
+	for _, format := range []Format{FormatCsv, FormatJson, FormatTxt} {
+		t.Run(string(format), func(t *testing.T) {
+			content, err := formatReport(types.Report{Results: results}, format, types.Config{})
+			if err != nil {
+				t.Fatalf("formatReport() unexpected error: %v", err)
+			}
+
+			got, err := Parse([]byte(content), format)
+			if err != nil {
+				t.Fatalf("Parse() unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(got, results) {
+				t.Errorf("Parse() = %+v, want %+v", got, results)
+			}
+		})
+	}
+}
+
+func TestParseSkipped(t *testing.T) {
+	results := []types.Result{
+		{Host: "10.0.0.1", Port: 22, Status: true},
+		{Host: "10.0.0.1", Port: 80, Status: false},
+		{Host: "10.0.0.1", Port: 443, Skipped: true},
+	}
+
+	for _, format := range []Format{FormatCsv, FormatJson, FormatNdjson, FormatTxt, FormatXml} {
+		t.Run(string(format), func(t *testing.T) {
+			content, err := formatReport(types.Report{Results: results}, format, types.Config{})
+			if err != nil {
+				t.Fatalf("formatReport() unexpected error: %v", err)
+			}
+
+			got, err := Parse([]byte(content), format)
+			if err != nil {
+				t.Fatalf("Parse() unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(got, results) {
+				t.Errorf("Parse() = %+v, want %+v", got, results)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   string
+		format Format
+	}{
+		{"invalid json", "[{", FormatJson},
+		{"invalid ndjson", "{\"port\":80}\n{", FormatNdjson},
+		{"csv without header", "", FormatCsv},
+		{"csv missing status column", "Port\n80\n", FormatCsv},
+		{"csv invalid port", "Port,Status\nhttp,true\n", FormatCsv},
+		{"txt invalid status", "Port   Status\n80     maybe\n", FormatTxt},
+		{"txt missing columns", "Port   Status\n80\n", FormatTxt},
+		{"invalid xml", "<nmaprun>", FormatXml},
+		{"unsupported format", "", FormatHtml},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.data), tt.format)
+			if err == nil {
+				t.Errorf("Parse() expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name    string
+		file    string
+		content string
+	}{
+		{"json by extension", "results.json", `[{"port":80,"status":true},{"port":443,"status":false},{"port":8080,"status":true}]`},
+		{"csv by extension", "results.csv", "Port,Status\n80,true\n443,false\n8080,true"},
+		{"txt by extension", "results.txt", "Port   Status\n80     true  \n443    false \n8080   true  \n"},
+		{"json by content", "results", "[\n" + `{"port":80,"status":true},{"port":443,"status":false},{"port":8080,"status":true}` + "\n]"},
+		{"csv by content", "results.out", "Port,Status\n80,true\n443,false\n8080,true"},
+		{"txt by content", "results.log", "Port   Status\n80     true\n443    false\n8080   true\n"},
+		{"ndjson by extension", "results.ndjson", `{"port":80,"status":true}` + "\n" + `{"port":443,"status":false}` + "\n" + `{"port":8080,"status":true}` + "\n"},
+		{
+			name:    "ndjson with metadata by content",
+			file:    "results.stream",
+			content: `{"record":"header","total":3}` + "\n" + `{"port":80,"status":true}` + "\n\n" + `{"port":443,"status":false}` + "\n" + `{"port":8080,"status":true}` + "\n" + `{"record":"footer","results":3,"open":2}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, tt.file)
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to write file: %v", err)
+			}
+
+			got, err := Load(path)
+			if err != nil {
+				t.Fatalf("Load() unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(got, testResults) {
+				t.Errorf("Load() = %+v, want %+v", got, testResults)
+			}
+		})
+	}
+
+	if _, err := Load(filepath.Join(dir, "missing.json")); err == nil {
+		t.Errorf("Load() expected error for missing file, got nil")
+	}
+}
+
+func TestParseReportWindow(t *testing.T) {
+	start := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	report := types.Report{
+		Results:  testResults,
+		Metadata: types.Metadata{Start: start, End: start.Add(90 * time.Second)},
+	}
+
+	tests := []struct {
+		format Format
+		cfg    types.Config
+		window bool
+	}{
+		{format: FormatJson, window: true},
+		{format: FormatNdjson, cfg: types.Config{NdjsonMeta: true}, window: true},
+		{format: FormatXml, window: true},
+		{format: FormatNdjson},
+		{format: FormatCsv},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			content, err := formatReport(report, tt.format, tt.cfg)
+			if err != nil {
+				t.Fatalf("formatReport() unexpected error: %v", err)
+			}
+
+			got, err := ParseReport([]byte(content), tt.format)
+			if err != nil {
+				t.Fatalf("ParseReport() unexpected error: %v", err)
+			}
+
+			if !tt.window {
+				if !got.Metadata.Start.IsZero() || !got.Metadata.End.IsZero() {
+					t.Errorf("ParseReport() window = %v - %v, want none", got.Metadata.Start, got.Metadata.End)
+				}
+				return
+			}
+			if !got.Metadata.Start.Equal(report.Metadata.Start) || !got.Metadata.End.Equal(report.Metadata.End) {
+				t.Errorf("ParseReport() window = %v - %v, want %v - %v", got.Metadata.Start, got.Metadata.End, report.Metadata.Start, report.Metadata.End)
+			}
+		})
+	}
+}
+
+func TestParseXML(t *testing.T) {
+	results := []types.Result{
+		{Host: "10.0.0.1", Port: 22, Status: true, Service: "ssh", Banner: "SSH-2.0-OpenSSH_9.6"},
+		{Host: "10.0.0.1", Port: 23, Status: false},
+		{Host: "example.com", Address: "93.184.216.34", Port: 443, Status: true, Service: "https"},
+		{Host: "db.internal", Port: 5432, Status: true},
+	}
+
+	content, err := toXML(types.Report{Results: results})
+	if err != nil {
+		t.Fatalf("toXML() unexpected error: %v", err)
+	}
+
+	got, err := Parse([]byte(content), FormatXml)
+	if err != nil {
+		t.Fatalf("Parse() unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(got, results) {
+		t.Errorf("Parse() = %+v, want %+v", got, results)
+	}
+}
+
+func TestParseNmapXML(t *testing.T) {
+	data := `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE nmaprun>
+<nmaprun scanner="nmap" args="nmap -sV 192.168.1.10" start="1767322800" version="7.95" xmloutputversion="1.05">
+<scaninfo type="syn" protocol="tcp" numservices="1000" services="1-1000"/>
+<host starttime="1767322800" endtime="1767322810"><status state="up" reason="echo-reply" reason_ttl="63"/>
+<address addr="192.168.1.10" addrtype="ipv4"/>
+<address addr="00:11:22:33:44:55" addrtype="mac"/>
+<hostnames><hostname name="router.lan" type="PTR"/></hostnames>
+<ports><extraports state="closed" count="997"/>
+<port protocol="tcp" portid="22"><state state="open" reason="syn-ack" reason_ttl="63"/><service name="ssh" product="OpenSSH" method="probed" conf="10"/><script id="ssh-hostkey" output="..."/><script id="banner" output="SSH-2.0-OpenSSH_9.6"/></port>
+<port protocol="tcp" portid="80"><state state="filtered" reason="no-response" reason_ttl="0"/></port>
+<port protocol="udp" portid="53"><state state="open" reason="udp-response" reason_ttl="63"/></port>
+</ports>
+</host>
+</nmaprun>`
+
+	expected := []types.Result{
+		{Host: "192.168.1.10", Port: 22, Status: true, Service: "ssh", Banner: "SSH-2.0-OpenSSH_9.6"},
+		{Host: "192.168.1.10", Port: 80, Skipped: true},
+	}
+
+	got, err := Parse([]byte(data), detectFormat([]byte(data)))
+	if err != nil {
+		t.Fatalf("Parse() unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Parse() = %+v, want %+v", got, expected)
+	}
+}
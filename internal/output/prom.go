@@ -0,0 +1,90 @@
+package output
+
+import (
+	"fmt"
+	"port-scanner/internal/types"
+	"strings"
+)
+
+const (
+	metricPortOpen     = "port_scanner_port_open"
+	metricDuration     = "port_scanner_scan_duration_seconds"
+	metricHostsScanned = "port_scanner_hosts_scanned"
+	metricPortsScanned = "port_scanner_ports_scanned"
+	metricPortsOpen    = "port_scanner_ports_open"
+	metricPortsSkipped = "port_scanner_ports_skipped"
+	metricLastScan     = "port_scanner_last_scan_timestamp_seconds"
+	metricLatency      = "port_scanner_port_latency_milliseconds"
+	metricViolation    = "port_scanner_policy_violation"
+	metricViolations   = "port_scanner_policy_violations"
+	metricProtocol     = "tcp"
+	MetricGauge        = "gauge"
+	MetricCounter      = "counter"
+)
+
+var promLabelReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func toProm(report types.Report) string {
+	var sb strings.Builder
+
+	open, skipped := 0, 0
+	for _, r := range report.Results {
+		if r.Status {
+			open++
+		}
+		if r.Skipped {
+			skipped++
+		}
+	}
+
+	WriteMetricHeader(&sb, metricPortOpen, MetricGauge, "Open TCP ports found by the last scan.")
+	for _, r := range report.Results {
+		if r.Status {
+			sb.WriteString(fmt.Sprintf("%s{%s} 1\n", metricPortOpen, portLabels(r)))
+		}
+	}
+
+	WriteMetricHeader(&sb, metricLatency, MetricGauge, "Connect latency of open TCP ports in milliseconds.")
+	for _, r := range report.Results {
+		if r.Status && r.Latency > 0 {
+			sb.WriteString(fmt.Sprintf("%s{%s} %g\n", metricLatency, portLabels(r), r.Latency))
+		}
+	}
+
+	hosts, ports := scannedCounts(report)
+	WriteMetric(&sb, metricHostsScanned, MetricGauge, "Hosts scanned by the last scan.", float64(hosts))
+	WriteMetric(&sb, metricPortsScanned, MetricGauge, "Ports scanned by the last scan.", float64(ports))
+	WriteMetric(&sb, metricPortsOpen, MetricGauge, "Open ports found by the last scan.", float64(open))
+	WriteMetric(&sb, metricPortsSkipped, MetricGauge, "Ports skipped after the host timeout by the last scan.", float64(skipped))
+
+	if len(report.Violations) > 0 {
+		WriteMetricHeader(&sb, metricViolation, MetricGauge, "Policy violations found by the last scan.")
+		for _, v := range report.Violations {
+			sb.WriteString(fmt.Sprintf(`%s{host="%s",port="%d",rule="%s"} 1`+"\n",
+				metricViolation, promLabelReplacer.Replace(v.Host), v.Port, v.Rule))
+		}
+		WriteMetric(&sb, metricViolations, MetricGauge, "Number of policy violations found by the last scan.", float64(len(report.Violations)))
+	}
+
+	if !report.Metadata.Start.IsZero() {
+		duration := report.Metadata.End.Sub(report.Metadata.Start).Seconds()
+		WriteMetric(&sb, metricDuration, MetricGauge, "Duration of the last scan in seconds.", duration)
+		WriteMetric(&sb, metricLastScan, MetricGauge, "Unix time the last scan finished.", float64(report.Metadata.End.Unix()))
+	}
+
+	return sb.String()
+}
+
+func WriteMetricHeader(sb *strings.Builder, name, kind, help string) {
+	sb.WriteString(fmt.Sprintf("# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind))
+}
+
+func WriteMetric(sb *strings.Builder, name, kind, help string, value float64) {
+	WriteMetricHeader(sb, name, kind, help)
+	sb.WriteString(fmt.Sprintf("%s %g\n", name, value))
+}
+
+func portLabels(r types.Result) string {
+	return fmt.Sprintf(`host="%s",port="%d",protocol="%s",service="%s"`,
+		promLabelReplacer.Replace(r.Host), r.Port, metricProtocol, promLabelReplacer.Replace(r.Service))
+}
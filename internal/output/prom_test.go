@@ -0,0 +1,90 @@
+package output
+
+import (
+	"port-scanner/internal/types"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestToProm(t *testing.T) {
+	start := time.Unix(1767323045, 0)
+
+	tests := []struct {
+		name        string
+		report      types.Report
+		contains    []string
+		notContains []string
+	}{
+		{
+			name:   "empty report",
+			report: types.Report{},
+			contains: []string{
+				"# TYPE port_scanner_port_open gauge\n",
+				"port_scanner_hosts_scanned 0\n",
+				"port_scanner_ports_scanned 0\n",
+				"port_scanner_ports_open 0\n",
+				"port_scanner_ports_skipped 0\n",
+			},
+			notContains: []string{"port_scanner_scan_duration_seconds"},
+		},
+		{
+			name: "open ports with labels",
+			report: types.Report{
+				Results: []types.Result{
+					{Host: "10.0.0.1", Port: 22, Status: true, Service: "ssh", Latency: 1.5},
+					{Host: "10.0.0.1", Port: 23, Status: false},
+					{Host: "10.0.0.2", Port: 8080, Status: true},
+				},
+				Metadata: types.Metadata{Start: start, End: start.Add(2500 * time.Millisecond)},
+			},
+			contains: []string{
+				"# HELP port_scanner_port_open Open TCP ports found by the last scan.\n",
+				`port_scanner_port_open{host="10.0.0.1",port="22",protocol="tcp",service="ssh"} 1` + "\n",
+				`port_scanner_port_open{host="10.0.0.2",port="8080",protocol="tcp",service=""} 1` + "\n",
+				`port_scanner_port_latency_milliseconds{host="10.0.0.1",port="22",protocol="tcp",service="ssh"} 1.5` + "\n",
+				"port_scanner_hosts_scanned 2\n",
+				"port_scanner_ports_scanned 3\n",
+				"port_scanner_ports_open 2\n",
+				"port_scanner_scan_duration_seconds 2.5\n",
+				"port_scanner_last_scan_timestamp_seconds 1.767323047e+09\n",
+			},
+			notContains: []string{`port="23"`},
+		},
+		{
+			name: "scanned counts from the summary",
+			report: types.Report{
+				Results: []types.Result{{Host: "10.0.0.1", Port: 22, Status: true}},
+				Summary: types.Summary{Hosts: 4, Ports: 100, Scanned: 400, Open: 1},
+			},
+			contains: []string{
+				"port_scanner_hosts_scanned 4\n",
+				"port_scanner_ports_scanned 400\n",
+				"port_scanner_ports_open 1\n",
+			},
+		},
+		{
+			name: "label values are escaped",
+			report: types.Report{Results: []types.Result{
+				{Host: `we"ird\host`, Port: 80, Status: true, Service: "http"},
+			}},
+			contains: []string{`host="we\"ird\\host"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := toProm(tt.report)
+			for _, want := range tt.contains {
+				if !strings.Contains(output, want) {
+					t.Errorf("toProm() missing %q in:\n%s", want, output)
+				}
+			}
+			for _, unwanted := range tt.notContains {
+				if strings.Contains(output, unwanted) {
+					t.Errorf("toProm() contains %q in:\n%s", unwanted, output)
+				}
+			}
+		})
+	}
+}
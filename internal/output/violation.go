@@ -0,0 +1,45 @@
+package output
+
+import (
+	"port-scanner/internal/types"
+	"strings"
+)
+
+const (
+	headerPolicy    = "Policy"
+	policySeparator = ";"
+)
+
+type violationKey struct {
+	host string
+	port int
+}
+
+type violationIndex map[violationKey][]types.Violation
+
+func indexViolations(violations []types.Violation) violationIndex {
+	index := make(violationIndex)
+	for _, v := range violations {
+		key := violationKey{host: v.Host, port: v.Port}
+		index[key] = append(index[key], v)
+	}
+	return index
+}
+
+func (i violationIndex) rules(host string, port int) string {
+	violations := i[violationKey{host: host, port: port}]
+	rules := make([]string, 0, len(violations))
+	for _, v := range violations {
+		rules = append(rules, v.Rule)
+	}
+	return strings.Join(rules, policySeparator)
+}
+
+func (i violationIndex) messages(host string, port int) string {
+	violations := i[violationKey{host: host, port: port}]
+	messages := make([]string, 0, len(violations))
+	for _, v := range violations {
+		messages = append(messages, v.Message)
+	}
+	return strings.Join(messages, policySeparator+" ")
+}
@@ -0,0 +1,130 @@
+package output
+
+import (
+	"encoding/json"
+	"port-scanner/internal/types"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var testViolations = []types.Violation{
+	{Host: "10.0.0.1", Port: 23, Rule: "forbidden-service", Message: "10.0.0.1: forbidden service telnet is running on port 23"},
+	{Host: "10.0.0.1", Port: 443, Rule: "required-port", Message: "10.0.0.1: required port 443 is closed or was not scanned"},
+}
+
+func TestFormatViolations(t *testing.T) {
+	report := types.Report{
+		Results: []types.Result{
+			{Host: "10.0.0.1", Port: 22, Status: true, Service: "ssh"},
+			{Host: "10.0.0.1", Port: 23, Status: true, Service: "telnet"},
+		},
+		Violations: testViolations,
+	}
+
+	tests := []struct {
+		format   Format
+		contains []string
+	}{
+		{FormatCsv, []string{"Host,Port,Status,Policy\n", "10.0.0.1,22,true,\n", "10.0.0.1,23,true,forbidden-service"}},
+		{FormatTxt, []string{"Status Policy\n", "23     true   forbidden-service\n"}},
+		{FormatJson, []string{`"violations": [`, `"rule": "required-port"`}},
+		{FormatNdjson, []string{`{"record":"violation","host":"10.0.0.1","port":23,"rule":"forbidden-service"`}},
+		{FormatXml, []string{
+			`<script id="policy" output="10.0.0.1: forbidden service telnet is running on port 23"></script>`,
+			`<hostscript>`,
+			`<script id="policy" output="10.0.0.1: required port 443 is closed or was not scanned"></script>`,
+		}},
+		{FormatGrep, []string{"\tPolicy: 23/forbidden-service, 443/required-port\n"}},
+		{FormatHtml, []string{"<b>2</b>policy violations", "<td>required-port</td>"}},
+		{FormatMarkdown, []string{"- Policy violations: 2\n", "## Policy violations", "| 10.0.0.1 | 443 | required-port |"}},
+		{FormatJunit, []string{`<testsuite name="policy" tests="2" failures="2"`, `type="forbidden-service"`}},
+		{FormatProm, []string{`port_scanner_policy_violation{host="10.0.0.1",port="443",rule="required-port"} 1`, "port_scanner_policy_violations 2\n"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			got, err := formatReport(report, tt.format, types.Config{})
+			if err != nil {
+				t.Fatalf("formatReport() unexpected error: %v", err)
+			}
+
+			for _, want := range tt.contains {
+				if !strings.Contains(got, want) {
+					t.Errorf("formatReport() = %q, want it to contain %q", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestFormatWithoutViolations(t *testing.T) {
+	report := types.Report{Results: []types.Result{{Host: "10.0.0.1", Port: 22, Status: true}}}
+
+	for _, format := range []Format{FormatCsv, FormatTxt, FormatJson, FormatMarkdown, FormatProm} {
+		t.Run(string(format), func(t *testing.T) {
+			got, err := formatReport(report, format, types.Config{})
+			if err != nil {
+				t.Fatalf("formatReport() unexpected error: %v", err)
+			}
+			if strings.Contains(strings.ToLower(got), "policy") || strings.Contains(got, "violations") {
+				t.Errorf("formatReport() = %q, want no policy output", got)
+			}
+		})
+	}
+}
+
+func TestParseViolations(t *testing.T) {
+	results := []types.Result{
+		{Host: "10.0.0.1", Port: 22, Status: true},
+		{Host: "10.0.0.1", Port: 23, Status: true},
+	}
+	report := types.Report{Results: results, Violations: testViolations}
+
+	for _, format := range []Format{FormatCsv, FormatTxt, FormatJson, FormatNdjson} {
+		t.Run(string(format), func(t *testing.T) {
+			content, err := formatReport(report, format, types.Config{})
+			if err != nil {
+				t.Fatalf("formatReport() unexpected error: %v", err)
+			}
+
+			got, err := Parse([]byte(content), format)
+			if err != nil {
+				t.Fatalf("Parse() unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, results) {
+				t.Errorf("Parse() = %+v, want %+v", got, results)
+			}
+		})
+	}
+}
+
+func TestStreamViolations(t *testing.T) {
+	stream := &Stream{meta: true, filter: func(types.Result) bool { return true }}
+	var sb strings.Builder
+	stream.writers = append(stream.writers, &sb)
+
+	stream.Start(1)
+	stream.Increment(types.Result{Host: "10.0.0.1", Port: 23, Status: true})
+	stream.Finish()
+	stream.Violations(testViolations)
+	if err := stream.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	var records []string
+	for _, line := range strings.Split(strings.TrimSpace(sb.String()), "\n") {
+		var record struct {
+			Record string `json:"record"`
+		}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("invalid record %q: %v", line, err)
+		}
+		records = append(records, record.Record)
+	}
+
+	expected := []string{"header", "", "violation", "violation", "footer"}
+	if !reflect.DeepEqual(records, expected) {
+		t.Errorf("records = %v, want %v", records, expected)
+	}
+}
@@ -0,0 +1,311 @@
+package output
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/netip"
+	"port-scanner/internal/types"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	xmlScanner       = "port-scanner"
+	xmlOutputVersion = "1.05"
+	xmlDoctype       = "<!DOCTYPE nmaprun>\n"
+	xmlScanType      = "connect"
+	xmlProtocol      = "tcp"
+	xmlStateOpen     = "open"
+	xmlStateClosed   = "closed"
+	xmlStateFiltered = "filtered"
+	xmlReasonOpen    = "syn-ack"
+	xmlReasonClosed  = "conn-refused"
+	xmlReasonSkipped = "no-response"
+	xmlHostUp        = "up"
+	xmlHostReason    = "user-set"
+	xmlServiceMethod = "table"
+	xmlServiceConf   = "3"
+	xmlBannerScript  = "banner"
+	xmlPolicyScript  = "policy"
+	xmlHostnameType  = "user"
+	xmlExitSuccess   = "success"
+	xmlDefaultLevel  = 0
+	xmlTimeFormat    = time.ANSIC
+	addrTypeIPv4     = "ipv4"
+	addrTypeIPv6     = "ipv6"
+	xmlSummaryFormat = "port-scanner done at %s; %d hosts scanned in %.2f seconds"
+)
+
+type xmlRun struct {
+	XMLName          xml.Name    `xml:"nmaprun"`
+	Scanner          string      `xml:"scanner,attr"`
+	Args             string      `xml:"args,attr"`
+	Start            int64       `xml:"start,attr"`
+	StartStr         string      `xml:"startstr,attr"`
+	Version          string      `xml:"version,attr"`
+	XMLOutputVersion string      `xml:"xmloutputversion,attr"`
+	ScanInfo         xmlScanInfo `xml:"scaninfo"`
+	Verbose          xmlLevel    `xml:"verbose"`
+	Debugging        xmlLevel    `xml:"debugging"`
+	Hosts            []xmlHost   `xml:"host"`
+	RunStats         xmlRunStats `xml:"runstats"`
+}
+
+type xmlScanInfo struct {
+	Type        string `xml:"type,attr"`
+	Protocol    string `xml:"protocol,attr"`
+	NumServices int    `xml:"numservices,attr"`
+	Services    string `xml:"services,attr"`
+}
+
+type xmlLevel struct {
+	Level int `xml:"level,attr"`
+}
+
+type xmlHost struct {
+	StartTime int64         `xml:"starttime,attr"`
+	EndTime   int64         `xml:"endtime,attr"`
+	Status    xmlStatus     `xml:"status"`
+	Addresses []xmlAddress  `xml:"address"`
+	Hostnames *xmlHostnames `xml:"hostnames"`
+	Ports     xmlPorts      `xml:"ports"`
+	Scripts   *xmlScripts   `xml:"hostscript"`
+}
+
+type xmlStatus struct {
+	State  string `xml:"state,attr"`
+	Reason string `xml:"reason,attr"`
+}
+
+type xmlAddress struct {
+	Addr     string `xml:"addr,attr"`
+	AddrType string `xml:"addrtype,attr"`
+}
+
+type xmlHostnames struct {
+	Hostnames []xmlHostname `xml:"hostname"`
+}
+
+type xmlHostname struct {
+	Name string `xml:"name,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type xmlPorts struct {
+	Ports []xmlPort `xml:"port"`
+}
+
+type xmlPort struct {
+	Protocol string      `xml:"protocol,attr"`
+	PortID   int         `xml:"portid,attr"`
+	State    xmlState    `xml:"state"`
+	Service  *xmlService `xml:"service"`
+	Scripts  []xmlScript `xml:"script"`
+}
+
+type xmlState struct {
+	State  string `xml:"state,attr"`
+	Reason string `xml:"reason,attr"`
+}
+
+type xmlService struct {
+	Name   string `xml:"name,attr"`
+	Method string `xml:"method,attr"`
+	Conf   string `xml:"conf,attr"`
+}
+
+type xmlScripts struct {
+	Scripts []xmlScript `xml:"script"`
+}
+
+type xmlScript struct {
+	ID     string `xml:"id,attr"`
+	Output string `xml:"output,attr"`
+}
+
+type xmlRunStats struct {
+	Finished xmlFinished `xml:"finished"`
+	Hosts    xmlHostStat `xml:"hosts"`
+}
+
+type xmlFinished struct {
+	Time    int64  `xml:"time,attr"`
+	TimeStr string `xml:"timestr,attr"`
+	Elapsed string `xml:"elapsed,attr"`
+	Summary string `xml:"summary,attr"`
+	Exit    string `xml:"exit,attr"`
+}
+
+type xmlHostStat struct {
+	Up    int `xml:"up,attr"`
+	Down  int `xml:"down,attr"`
+	Total int `xml:"total,attr"`
+}
+
+func toXML(report types.Report) (string, error) {
+	start, end := report.Metadata.Start, report.Metadata.End
+	elapsed := end.Sub(start).Seconds()
+	hosts := xmlHosts(report.Results, report.Violations, start, end)
+
+	run := xmlRun{
+		Scanner:          xmlScanner,
+		Args:             strings.Join(report.Metadata.Args, " "),
+		Start:            start.Unix(),
+		StartStr:         start.Format(xmlTimeFormat),
+		Version:          report.Metadata.Version,
+		XMLOutputVersion: xmlOutputVersion,
+		ScanInfo:         xmlScanInfoFor(report.Results),
+		Verbose:          xmlLevel{Level: xmlDefaultLevel},
+		Debugging:        xmlLevel{Level: xmlDefaultLevel},
+		Hosts:            hosts,
+		RunStats: xmlRunStats{
+			Finished: xmlFinished{
+				Time:    end.Unix(),
+				TimeStr: end.Format(xmlTimeFormat),
+				Elapsed: fmt.Sprintf("%.2f", elapsed),
+				Summary: fmt.Sprintf(xmlSummaryFormat, end.Format(xmlTimeFormat), len(hosts), elapsed),
+				Exit:    xmlExitSuccess,
+			},
+			Hosts: xmlHostStat{Up: len(hosts), Total: len(hosts)},
+		},
+	}
+
+	data, err := xml.MarshalIndent(run, "", "  ")
+	if err != nil {
+		return "", writeFileError
+	}
+
+	return xml.Header + xmlDoctype + string(data) + "\n", nil
+}
+
+func xmlScanInfoFor(results []types.Result) xmlScanInfo {
+	seen := make(map[int]bool)
+	ports := make([]int, 0)
+	for _, r := range results {
+		if !seen[r.Port] {
+			seen[r.Port] = true
+			ports = append(ports, r.Port)
+		}
+	}
+
+	return xmlScanInfo{
+		Type:        xmlScanType,
+		Protocol:    xmlProtocol,
+		NumServices: len(ports),
+		Services:    portRanges(ports),
+	}
+}
+
+func xmlHosts(results []types.Result, violations []types.Violation, start, end time.Time) []xmlHost {
+	hosts := make([]xmlHost, 0)
+	index := make(map[string]int)
+	policy := indexViolations(violations)
+	scanned := make(map[violationKey]bool)
+
+	for _, r := range results {
+		i, ok := index[r.Host]
+		if !ok {
+			i = len(hosts)
+			index[r.Host] = i
+			hosts = append(hosts, newXMLHost(r, start, end))
+		}
+
+		port := newXMLPort(r)
+		if messages := policy.messages(r.Host, r.Port); messages != "" {
+			port.Scripts = append(port.Scripts, xmlScript{ID: xmlPolicyScript, Output: messages})
+		}
+		hosts[i].Ports.Ports = append(hosts[i].Ports.Ports, port)
+		scanned[violationKey{host: r.Host, port: r.Port}] = true
+	}
+
+	for _, v := range violations {
+		i, ok := index[v.Host]
+		if !ok || scanned[violationKey{host: v.Host, port: v.Port}] {
+			continue
+		}
+		if hosts[i].Scripts == nil {
+			hosts[i].Scripts = &xmlScripts{}
+		}
+		hosts[i].Scripts.Scripts = append(hosts[i].Scripts.Scripts, xmlScript{ID: xmlPolicyScript, Output: v.Message})
+	}
+
+	return hosts
+}
+
+func newXMLHost(r types.Result, start, end time.Time) xmlHost {
+	h := xmlHost{
+		StartTime: start.Unix(),
+		EndTime:   end.Unix(),
+		Status:    xmlStatus{State: xmlHostUp, Reason: xmlHostReason},
+	}
+
+	if _, err := netip.ParseAddr(r.Host); err != nil && r.Host != "" {
+		h.Hostnames = &xmlHostnames{Hostnames: []xmlHostname{{Name: r.Host, Type: xmlHostnameType}}}
+	}
+	if addr, ok := resultAddress(r); ok {
+		h.Addresses = []xmlAddress{newXMLAddress(addr)}
+	}
+	return h
+}
+
+func resultAddress(r types.Result) (netip.Addr, bool) {
+	if addr, err := netip.ParseAddr(r.Host); err == nil {
+		return addr, true
+	}
+	addr, err := netip.ParseAddr(r.Address)
+	return addr, err == nil
+}
+
+func newXMLAddress(addr netip.Addr) xmlAddress {
+	if addr.Is4() {
+		return xmlAddress{Addr: addr.String(), AddrType: addrTypeIPv4}
+	}
+	return xmlAddress{Addr: addr.String(), AddrType: addrTypeIPv6}
+}
+
+func newXMLPort(r types.Result) xmlPort {
+	port := xmlPort{
+		Protocol: xmlProtocol,
+		PortID:   r.Port,
+		State:    xmlState{State: xmlStateClosed, Reason: xmlReasonClosed},
+	}
+
+	if r.Status {
+		port.State = xmlState{State: xmlStateOpen, Reason: xmlReasonOpen}
+	}
+	if r.Skipped {
+		port.State = xmlState{State: xmlStateFiltered, Reason: xmlReasonSkipped}
+	}
+	if r.Service != "" {
+		port.Service = &xmlService{Name: r.Service, Method: xmlServiceMethod, Conf: xmlServiceConf}
+	}
+	if r.Banner != "" {
+		port.Scripts = []xmlScript{{ID: xmlBannerScript, Output: r.Banner}}
+	}
+
+	return port
+}
+
+func portRanges(ports []int) string {
+	sorted := append([]int{}, ports...)
+	slices.Sort(sorted)
+
+	ranges := make([]string, 0)
+	for i := 0; i < len(sorted); {
+		j := i
+		for j+1 < len(sorted) && sorted[j+1] == sorted[j]+1 {
+			j++
+		}
+
+		if i == j {
+			ranges = append(ranges, strconv.Itoa(sorted[i]))
+		} else {
+			ranges = append(ranges, fmt.Sprintf("%d-%d", sorted[i], sorted[j]))
+		}
+		i = j + 1
+	}
+
+	return strings.Join(ranges, ",")
+}
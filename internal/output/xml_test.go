@@ -0,0 +1,125 @@
+package output
+
+import (
+	"encoding/xml"
+	"port-scanner/internal/types"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestToXML(t *testing.T) {
+	start := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	report := types.Report{
+		Results: []types.Result{
+			{Host: "10.0.0.1", Port: 22, Status: true, Service: "ssh", Banner: "SSH-2.0-OpenSSH_9.6"},
+			{Host: "10.0.0.1", Port: 23, Status: false},
+			{Host: "example.com", Address: "93.184.216.34", Port: 443, Status: true, Service: "https"},
+			{Host: "::1", Port: 80, Status: false},
+			{Host: "db.internal", Port: 5432, Status: true, Service: "postgresql"},
+		},
+		Metadata: types.Metadata{
+			Version: "1.0.0",
+			Args:    []string{"port-scanner", "-a", "10.0.0.1,example.com,::1,db.internal", "-f", "xml"},
+			Start:   start,
+			End:     start.Add(1500 * time.Millisecond),
+		},
+	}
+
+	output, err := toXML(report)
+	if err != nil {
+		t.Fatalf("toXML() error = %v", err)
+	}
+
+	if !strings.HasPrefix(output, xml.Header+"<!DOCTYPE nmaprun>\n<nmaprun") {
+		t.Errorf("toXML() missing xml header and doctype:\n%s", output)
+	}
+
+	var run xmlRun
+	if err := xml.Unmarshal([]byte(output), &run); err != nil {
+		t.Fatalf("toXML() produced invalid XML: %v", err)
+	}
+
+	if run.Args != "port-scanner -a 10.0.0.1,example.com,::1,db.internal -f xml" || run.Start != start.Unix() || run.Version != "1.0.0" {
+		t.Errorf("nmaprun = %+v", run)
+	}
+	if run.ScanInfo != (xmlScanInfo{Type: "connect", Protocol: "tcp", NumServices: 5, Services: "22-23,80,443,5432"}) {
+		t.Errorf("scaninfo = %+v", run.ScanInfo)
+	}
+	if run.Verbose != (xmlLevel{Level: 0}) || run.Debugging != (xmlLevel{Level: 0}) || !strings.Contains(output, "<verbose level=\"0\"></verbose>") {
+		t.Errorf("verbose = %+v, debugging = %+v", run.Verbose, run.Debugging)
+	}
+	if run.RunStats.Finished.Elapsed != "1.50" || run.RunStats.Finished.Time != start.Unix()+1 {
+		t.Errorf("runstats = %+v", run.RunStats)
+	}
+	if run.RunStats.Hosts != (xmlHostStat{Up: 4, Total: 4}) {
+		t.Errorf("runstats hosts = %+v, want 4 up", run.RunStats.Hosts)
+	}
+
+	if len(run.Hosts) != 4 {
+		t.Fatalf("toXML() wrote %d hosts, want 4", len(run.Hosts))
+	}
+
+	addresses := [][]xmlAddress{
+		{{Addr: "10.0.0.1", AddrType: "ipv4"}},
+		{{Addr: "93.184.216.34", AddrType: "ipv4"}},
+		{{Addr: "::1", AddrType: "ipv6"}},
+		nil,
+	}
+	for i, host := range run.Hosts {
+		if !reflect.DeepEqual(host.Addresses, addresses[i]) {
+			t.Errorf("host %d addresses = %+v, want %+v", i, host.Addresses, addresses[i])
+		}
+	}
+
+	unresolved := run.Hosts[3]
+	if unresolved.Hostnames == nil || unresolved.Hostnames.Hostnames[0].Name != "db.internal" || len(unresolved.Ports.Ports) != 1 {
+		t.Errorf("host 3 = %+v, want db.internal with its port and no address", unresolved)
+	}
+	if run.Hosts[0].Hostnames != nil {
+		t.Errorf("host 0 hostnames = %+v, want none for an ip address", run.Hosts[0].Hostnames)
+	}
+
+	if run.Hosts[1].Hostnames == nil || run.Hosts[1].Hostnames.Hostnames[0].Name != "example.com" {
+		t.Errorf("host 1 hostnames = %+v, want example.com", run.Hosts[1].Hostnames)
+	}
+
+	ssh := run.Hosts[0].Ports.Ports[0]
+	expected := xmlPort{
+		Protocol: "tcp",
+		PortID:   22,
+		State:    xmlState{State: "open", Reason: "syn-ack"},
+		Service:  &xmlService{Name: "ssh", Method: "table", Conf: "3"},
+		Scripts:  []xmlScript{{ID: "banner", Output: "SSH-2.0-OpenSSH_9.6"}},
+	}
+	if !reflect.DeepEqual(ssh, expected) {
+		t.Errorf("port 22 = %+v, want %+v", ssh, expected)
+	}
+
+	telnet := run.Hosts[0].Ports.Ports[1]
+	if telnet.State.State != "closed" || telnet.Service != nil || telnet.Scripts != nil {
+		t.Errorf("port 23 = %+v, want closed without service", telnet)
+	}
+}
+
+func TestPortRanges(t *testing.T) {
+	tests := []struct {
+		name     string
+		ports    []int
+		expected string
+	}{
+		{"empty", nil, ""},
+		{"single port", []int{80}, "80"},
+		{"range", []int{3, 1, 2}, "1-3"},
+		{"mixed", []int{443, 22, 80, 21, 8080, 8081}, "21-22,80,443,8080-8081"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := portRanges(tt.ports); got != tt.expected {
+				t.Errorf("portRanges(%v) = %q, want %q", tt.ports, got, tt.expected)
+			}
+		})
+	}
+}
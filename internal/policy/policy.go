@@ -0,0 +1,145 @@
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"port-scanner/internal/config"
+	"port-scanner/internal/hostmatch"
+	"port-scanner/internal/types"
+	"slices"
+	"strings"
+)
+
+const (
+	RuleNotAllowed       = "port-not-allowed"
+	RuleForbiddenService = "forbidden-service"
+	RuleRequired         = "required-port"
+)
+
+var (
+	readPolicyError    = errors.New("failed to read policy file")
+	invalidPolicyError = errors.New("invalid policy file")
+)
+
+type Rule struct {
+	Allowed   []int    `yaml:"allowed"`
+	Forbidden []string `yaml:"forbidden-services"`
+	Required  []int    `yaml:"required"`
+}
+
+type Policy struct {
+	Default Rule            `yaml:"default"`
+	Hosts   map[string]Rule `yaml:"hosts"`
+}
+
+func Load(path string) (Policy, error) {
+	if path == "" {
+		return Policy{}, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Policy{}, fmt.Errorf("%w: %s", readPolicyError, path)
+	}
+
+	var p Policy
+	err = config.Decode(data, &p)
+	if err != nil {
+		return Policy{}, fmt.Errorf("%w: %s: %v", invalidPolicyError, path, err)
+	}
+
+	err = hostmatch.Validate(p.Hosts)
+	if err != nil {
+		return Policy{}, fmt.Errorf("%w: %s: %v", invalidPolicyError, path, err)
+	}
+
+	for host, rule := range p.Hosts {
+		err = rule.validate()
+		if err != nil {
+			return Policy{}, fmt.Errorf("%w: %s: %s: %v", invalidPolicyError, path, host, err)
+		}
+	}
+
+	err = p.Default.validate()
+	if err != nil {
+		return Policy{}, fmt.Errorf("%w: %s: default: %v", invalidPolicyError, path, err)
+	}
+
+	return p, nil
+}
+
+func (r Rule) validate() error {
+	for _, port := range slices.Concat(r.Allowed, r.Required) {
+		if port < 1 || port > 65535 {
+			return fmt.Errorf("invalid port %d", port)
+		}
+	}
+	return nil
+}
+
+func (p Policy) For(host string) Rule {
+	return hostmatch.Lookup(p.Hosts, p.Default, host)
+}
+
+func (p Policy) Evaluate(results []types.Result) []types.Violation {
+	hosts := make([]string, 0)
+	open := make(map[string][]types.Result)
+
+	for _, r := range results {
+		if _, ok := open[r.Host]; !ok {
+			hosts = append(hosts, r.Host)
+			open[r.Host] = []types.Result{}
+		}
+		if r.Status {
+			open[r.Host] = append(open[r.Host], r)
+		}
+	}
+
+	violations := make([]types.Violation, 0)
+	for _, host := range hosts {
+		violations = append(violations, p.For(host).evaluate(host, open[host])...)
+	}
+	return violations
+}
+
+func (r Rule) evaluate(host string, open []types.Result) []types.Violation {
+	violations := make([]types.Violation, 0)
+	name := hostmatch.Name(host)
+
+	for _, result := range open {
+		if r.Allowed != nil && !slices.Contains(r.Allowed, result.Port) {
+			violations = append(violations, types.Violation{
+				Host:    host,
+				Port:    result.Port,
+				Rule:    RuleNotAllowed,
+				Message: fmt.Sprintf("%s: port %d is open but not allowed", name, result.Port),
+			})
+		}
+
+		if result.Service != "" && slices.ContainsFunc(r.Forbidden, func(service string) bool {
+			return strings.EqualFold(service, result.Service)
+		}) {
+			violations = append(violations, types.Violation{
+				Host:    host,
+				Port:    result.Port,
+				Rule:    RuleForbiddenService,
+				Message: fmt.Sprintf("%s: forbidden service %s is running on port %d", name, result.Service, result.Port),
+			})
+		}
+	}
+
+	for _, port := range r.Required {
+		if slices.ContainsFunc(open, func(result types.Result) bool { return result.Port == port }) {
+			continue
+		}
+		violations = append(violations, types.Violation{
+			Host:    host,
+			Port:    port,
+			Rule:    RuleRequired,
+			Message: fmt.Sprintf("%s: required port %d is closed or was not scanned", name, port),
+		})
+	}
+
+	return violations
+}
@@ -0,0 +1,145 @@
+package policy
+
+import (
+	"os"
+	"path/filepath"
+	"port-scanner/internal/types"
+	"reflect"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name     string
+		content  string
+		expected Policy
+		wantErr  bool
+	}{
+		{
+			name: "valid policy",
+			content: "default:\n  forbidden-services: [telnet, ftp]\n" +
+				"hosts:\n  10.0.0.0/24:\n    allowed: [22, 443]\n    required: [443]\n",
+			expected: Policy{
+				Default: Rule{Forbidden: []string{"telnet", "ftp"}},
+				Hosts:   map[string]Rule{"10.0.0.0/24": {Allowed: []int{22, 443}, Required: []int{443}}},
+			},
+		},
+		{name: "empty file", content: ""},
+		{name: "unknown field", content: "default:\n  denied: [23]\n", wantErr: true},
+		{name: "invalid cidr", content: "hosts:\n  10.0.0.0/40:\n    allowed: [22]\n", wantErr: true},
+		{name: "invalid port", content: "default:\n  required: [70000]\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, "policy.yaml")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to write file: %v", err)
+			}
+
+			got, err := Load(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Load() = %+v, want %+v", got, tt.expected)
+			}
+		})
+	}
+
+	if _, err := Load(filepath.Join(dir, "missing.yaml")); err == nil {
+		t.Errorf("Load() expected error for missing file, got nil")
+	}
+
+	if got, err := Load(""); err != nil || !reflect.DeepEqual(got, Policy{}) {
+		t.Errorf("Load(\"\") = %+v, %v, want empty policy", got, err)
+	}
+}
+
+func TestFor(t *testing.T) {
+	p := Policy{
+		Default: Rule{Required: []int{1}},
+		Hosts: map[string]Rule{
+			"10.0.0.0/8":  {Required: []int{8}},
+			"10.0.0.0/24": {Required: []int{24}},
+			"10.0.0.5":    {Required: []int{5}},
+			"db.internal": {Required: []int{5432}},
+		},
+	}
+
+	tests := []struct {
+		host     string
+		expected int
+	}{
+		{"10.0.0.5", 5},
+		{"10.0.0.6", 24},
+		{"10.1.0.1", 8},
+		{"192.168.1.1", 1},
+		{"db.internal", 5432},
+		{"web.internal", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			got := p.For(tt.host)
+			if len(got.Required) != 1 || got.Required[0] != tt.expected {
+				t.Errorf("For(%q) = %+v, want required %d", tt.host, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	results := []types.Result{
+		{Host: "10.0.0.1", Port: 22, Status: true, Service: "ssh"},
+		{Host: "10.0.0.1", Port: 23, Status: true, Service: "telnet"},
+		{Host: "10.0.0.1", Port: 443, Status: false},
+		{Host: "10.0.0.2", Port: 22, Status: true, Service: "ssh"},
+		{Host: "10.0.0.2", Port: 443, Status: true, Service: "https"},
+		{Host: "192.168.1.1", Port: 21, Status: true, Service: "ftp"},
+	}
+
+	tests := []struct {
+		name     string
+		policy   Policy
+		expected []types.Violation
+	}{
+		{
+			name:     "empty policy",
+			expected: []types.Violation{},
+		},
+		{
+			name: "rules per cidr",
+			policy: Policy{
+				Default: Rule{Forbidden: []string{"FTP", "telnet"}},
+				Hosts: map[string]Rule{
+					"10.0.0.0/24": {Allowed: []int{22, 443}, Forbidden: []string{"telnet"}, Required: []int{443}},
+				},
+			},
+			expected: []types.Violation{
+				{Host: "10.0.0.1", Port: 23, Rule: RuleNotAllowed, Message: "10.0.0.1: port 23 is open but not allowed"},
+				{Host: "10.0.0.1", Port: 23, Rule: RuleForbiddenService, Message: "10.0.0.1: forbidden service telnet is running on port 23"},
+				{Host: "10.0.0.1", Port: 443, Rule: RuleRequired, Message: "10.0.0.1: required port 443 is closed or was not scanned"},
+				{Host: "192.168.1.1", Port: 21, Rule: RuleForbiddenService, Message: "192.168.1.1: forbidden service ftp is running on port 21"},
+			},
+		},
+		{
+			name:   "nothing allowed",
+			policy: Policy{Hosts: map[string]Rule{"192.168.1.1": {Allowed: []int{}}}},
+			expected: []types.Violation{
+				{Host: "192.168.1.1", Port: 21, Rule: RuleNotAllowed, Message: "192.168.1.1: port 21 is open but not allowed"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.policy.Evaluate(results)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Evaluate() = %+v, want %+v", got, tt.expected)
+			}
+		})
+	}
+}
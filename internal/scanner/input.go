@@ -0,0 +1,127 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"port-scanner/internal/output"
+	"port-scanner/internal/types"
+)
+
+var (
+	missingInputHostError = errors.New("input results without a host require an address")
+)
+
+type inputTarget struct {
+	host string
+	port int
+}
+
+func loadInputTargets(cfg types.Config) ([]inputTarget, error) {
+	results, err := output.Load(cfg.InputResults)
+	if err != nil {
+		return nil, err
+	}
+
+	var fallback []string
+	targets := make([]inputTarget, 0, len(results))
+	seen := make(map[inputTarget]bool)
+
+	for _, r := range results {
+		if cfg.InputOpenOnly && !r.Status {
+			continue
+		}
+
+		if r.Port < minPortNumber || r.Port > maxPortNumber {
+			return nil, fmt.Errorf("%s: %w", cfg.InputResults, invalidPortRangeError)
+		}
+
+		hosts := []string{r.Host}
+		if r.Host == "" {
+			if fallback == nil {
+				fallback, err = parseTargets(cfg.Address)
+				if err != nil {
+					return nil, err
+				}
+			}
+			if len(fallback) == 0 {
+				return nil, fmt.Errorf("%s: %w", cfg.InputResults, missingInputHostError)
+			}
+			hosts = fallback
+		}
+
+		for _, host := range hosts {
+			target := inputTarget{host: host, port: r.Port}
+			if !seen[target] {
+				seen[target] = true
+				targets = append(targets, target)
+			}
+		}
+	}
+
+	return targets, nil
+}
+
+func createInputTasks(ctx context.Context, targets []inputTarget, excl *exclusions, shard Shard) (chan types.Task, types.Summary) {
+	if excl == nil {
+		excl = &exclusions{}
+	}
+
+	hosts := make(map[string]bool)
+	ports := make(map[int]bool)
+	excludedHosts := make(map[string]bool)
+	excludedPorts := make(map[int]bool)
+	skipped := make(map[string]bool)
+	scanned := 0
+
+	for i, target := range targets {
+		if _, ok := skipped[target.host]; !ok {
+			skipped[target.host] = excl.excludesHost(target.host)
+		}
+
+		switch {
+		case skipped[target.host]:
+			excludedHosts[target.host] = true
+		case excl.excludesPort(target.port):
+			excludedPorts[target.port] = true
+		default:
+			hosts[target.host] = true
+			ports[target.port] = true
+			if shard.includes(i, target.host) {
+				scanned++
+			}
+		}
+	}
+
+	summary := types.Summary{
+		Hosts:         len(hosts),
+		Ports:         len(ports),
+		Scanned:       scanned,
+		ExcludedHosts: len(excludedHosts),
+		ExcludedPorts: len(excludedPorts),
+	}
+
+	tasks := streamTasks(ctx, func(send func(host string, port int) bool) {
+		for i, target := range targets {
+			if skipped[target.host] || excl.excludesPort(target.port) || !shard.includes(i, target.host) {
+				continue
+			}
+			if !send(target.host, target.port) {
+				return
+			}
+		}
+	})
+	return tasks, summary
+}
+
+func inputHosts(targets []inputTarget) []string {
+	hosts := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, target := range targets {
+		if !seen[target.host] {
+			seen[target.host] = true
+			hosts = append(hosts, target.host)
+		}
+	}
+	return hosts
+}
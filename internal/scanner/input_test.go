@@ -0,0 +1,173 @@
+package scanner
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"port-scanner/internal/types"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestLoadInputTargets(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name     string
+		file     string
+		content  string
+		cfg      types.Config
+		expected []inputTarget
+		wantErr  bool
+	}{
+		{
+			name:    "json results",
+			file:    "results.json",
+			content: `[{"host":"10.0.0.1","port":22,"status":true},{"host":"10.0.0.1","port":23,"status":false},{"host":"10.0.0.2","port":80,"status":true}]`,
+			expected: []inputTarget{
+				{host: "10.0.0.1", port: 22},
+				{host: "10.0.0.1", port: 23},
+				{host: "10.0.0.2", port: 80},
+			},
+		},
+		{
+			name:    "open only",
+			file:    "results.csv",
+			content: "Host,Port,Status\n10.0.0.1,22,true\n10.0.0.1,23,false\n10.0.0.2,80,true\n",
+			cfg:     types.Config{InputOpenOnly: true},
+			expected: []inputTarget{
+				{host: "10.0.0.1", port: 22},
+				{host: "10.0.0.2", port: 80},
+			},
+		},
+		{
+			name:     "duplicates",
+			file:     "results.ndjson",
+			content:  `{"host":"10.0.0.1","port":22,"status":true}` + "\n" + `{"host":"10.0.0.1","port":22,"status":true}` + "\n",
+			expected: []inputTarget{{host: "10.0.0.1", port: 22}},
+		},
+		{
+			name:    "nmap xml",
+			file:    "results.xml",
+			content: `<nmaprun><host><address addr="192.168.1.10" addrtype="ipv4"/><ports><port protocol="tcp" portid="22"><state state="open"/></port><port protocol="udp" portid="53"><state state="open"/></port></ports></host></nmaprun>`,
+			expected: []inputTarget{
+				{host: "192.168.1.10", port: 22},
+			},
+		},
+		{
+			name:    "results without host use address",
+			file:    "results.txt",
+			content: "Port   Status\n80     true\n443    false\n",
+			cfg:     types.Config{Address: "10.0.0.1,10.0.0.2", InputOpenOnly: true},
+			expected: []inputTarget{
+				{host: "10.0.0.1", port: 80},
+				{host: "10.0.0.2", port: 80},
+			},
+		},
+		{
+			name:    "results without host or address",
+			file:    "hostless.txt",
+			content: "Port   Status\n80     true\n",
+			wantErr: true,
+		},
+		{
+			name:    "invalid port",
+			file:    "invalid.json",
+			content: `[{"host":"10.0.0.1","port":70000,"status":true}]`,
+			wantErr: true,
+		},
+		{
+			name:    "missing file",
+			file:    "missing.json",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, tt.file)
+			if tt.content != "" {
+				if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+					t.Fatalf("Failed to write file: %v", err)
+				}
+			}
+
+			cfg := tt.cfg
+			cfg.InputResults = path
+			got, err := loadInputTargets(cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("loadInputTargets() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("loadInputTargets() = %+v, want %+v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCreateInputTasks(t *testing.T) {
+	excl, err := newExclusions([]string{"10.0.0.2"}, "", "3306")
+	if err != nil {
+		t.Fatalf("newExclusions() unexpected error: %v", err)
+	}
+
+	targets := []inputTarget{
+		{host: "10.0.0.1", port: 22},
+		{host: "10.0.0.1", port: 3306},
+		{host: "10.0.0.2", port: 22},
+		{host: "10.0.0.3", port: 443},
+	}
+
+	tasks, summary := createInputTasks(context.Background(), targets, excl, Shard{})
+
+	expected := types.Summary{Hosts: 2, Ports: 2, Scanned: 2, ExcludedHosts: 1, ExcludedPorts: 1}
+	if summary != expected {
+		t.Errorf("Summary = %+v, want %+v", summary, expected)
+	}
+
+	expectedTasks := []types.Task{
+		{Index: 0, Host: "10.0.0.1", Port: 22},
+		{Index: 1, Host: "10.0.0.3", Port: 443},
+	}
+
+	var got []types.Task
+	for task := range tasks {
+		got = append(got, task)
+	}
+	if !reflect.DeepEqual(got, expectedTasks) {
+		t.Errorf("tasks = %+v, want %+v", got, expectedTasks)
+	}
+}
+
+func TestScanInputResults(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer func() {
+		_ = listener.Close()
+	}()
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	path := filepath.Join(t.TempDir(), "results.csv")
+	content := "Host,Port,Status\n127.0.0.1," + strconv.Itoa(port) + ",true\n127.0.0.1,1,false\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	cfg := types.Config{InputResults: path, InputOpenOnly: true, Mode: "rapid", Quiet: true}
+	report, err := Scan(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("Scan() unexpected error: %v", err)
+	}
+
+	expected := []types.Result{{Host: "127.0.0.1", Port: port, Status: true}}
+	if len(report.Results) != 1 || report.Results[0].Port != port || !report.Results[0].Status {
+		t.Errorf("Scan() results = %+v, want %+v", report.Results, expected)
+	}
+	if report.Metadata.Input != path || !reflect.DeepEqual(report.Metadata.Targets, []string{"127.0.0.1"}) {
+		t.Errorf("Scan() metadata = %+v, want input %q and targets [127.0.0.1]", report.Metadata, path)
+	}
+}
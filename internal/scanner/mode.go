@@ -12,17 +12,30 @@ const (
 	ModeStealth = "stealth"
 	ModeDefault = "default"
 	ModeRapid   = "rapid"
+
+	ModeParanoid   = "paranoid"
+	ModeSneaky     = "sneaky"
+	ModePolite     = "polite"
+	ModeNormal     = "normal"
+	ModeAggressive = "aggressive"
+	ModeInsane     = "insane"
 )
 
 type metadata struct {
 	workerCount int
 	timeout     time.Duration
+	delay       time.Duration
+	jitter      time.Duration
+	retries     int
+	hostTimeout time.Duration
 }
 
 var metadataMap = map[Mode]metadata{
 	ModeStealth: {
 		workerCount: 10,
 		timeout:     5 * time.Second,
+		delay:       250 * time.Millisecond,
+		jitter:      250 * time.Millisecond,
 	},
 	ModeDefault: {
 		workerCount: 100,
@@ -32,6 +45,52 @@ var metadataMap = map[Mode]metadata{
 		workerCount: 1000,
 		timeout:     500 * time.Millisecond,
 	},
+	ModeParanoid: {
+		workerCount: 1,
+		timeout:     10 * time.Second,
+		delay:       5 * time.Minute,
+		jitter:      1 * time.Minute,
+		retries:     2,
+	},
+	ModeSneaky: {
+		workerCount: 1,
+		timeout:     5 * time.Second,
+		delay:       15 * time.Second,
+		jitter:      5 * time.Second,
+		retries:     2,
+	},
+	ModePolite: {
+		workerCount: 10,
+		timeout:     2 * time.Second,
+		delay:       400 * time.Millisecond,
+		jitter:      100 * time.Millisecond,
+		retries:     2,
+	},
+	ModeNormal: {
+		workerCount: 100,
+		timeout:     1 * time.Second,
+		retries:     1,
+	},
+	ModeAggressive: {
+		workerCount: 500,
+		timeout:     500 * time.Millisecond,
+		retries:     1,
+		hostTimeout: 30 * time.Minute,
+	},
+	ModeInsane: {
+		workerCount: 1000,
+		timeout:     250 * time.Millisecond,
+		hostTimeout: 15 * time.Minute,
+	},
+}
+
+var timingTemplates = map[string]Mode{
+	"t0": ModeParanoid,
+	"t1": ModeSneaky,
+	"t2": ModePolite,
+	"t3": ModeNormal,
+	"t4": ModeAggressive,
+	"t5": ModeInsane,
 }
 
 func (m Mode) WorkerCount() int {
@@ -42,15 +101,31 @@ func (m Mode) Timeout() time.Duration {
 	return metadataMap[m].timeout
 }
 
+func (m Mode) Delay() time.Duration {
+	return metadataMap[m].delay
+}
+
+func (m Mode) Jitter() time.Duration {
+	return metadataMap[m].jitter
+}
+
+func (m Mode) Retries() int {
+	return metadataMap[m].retries
+}
+
+func (m Mode) HostTimeout() time.Duration {
+	return metadataMap[m].hostTimeout
+}
+
 func ParseMode(s string) (Mode, error) {
-	switch strings.ToLower(s) {
-	case "stealth":
-		return ModeStealth, nil
-	case "default":
-		return ModeDefault, nil
-	case "rapid":
-		return ModeRapid, nil
-	default:
-		return "", fmt.Errorf("invalid mode: %q", s)
+	name := strings.ToLower(s)
+	if mode, ok := timingTemplates[name]; ok {
+		return mode, nil
 	}
+
+	if _, ok := metadataMap[Mode(name)]; ok {
+		return Mode(name), nil
+	}
+
+	return "", fmt.Errorf("invalid mode: %q", s)
 }
@@ -15,6 +15,16 @@ func TestParseMode(t *testing.T) {
 		{"default", ModeDefault, false},
 		{"rapid", ModeRapid, false},
 		{"STEALTH", ModeStealth, false}, // case-insensitive
+		{"paranoid", ModeParanoid, false},
+		{"sneaky", ModeSneaky, false},
+		{"polite", ModePolite, false},
+		{"normal", ModeNormal, false},
+		{"aggressive", ModeAggressive, false},
+		{"insane", ModeInsane, false},
+		{"t0", ModeParanoid, false},
+		{"T3", ModeNormal, false},
+		{"t5", ModeInsane, false},
+		{"t6", "", true},
 		{"invalid", "", true},
 		{"", "", true},
 	}
@@ -69,3 +79,46 @@ func TestModeTimeout(t *testing.T) {
 		})
 	}
 }
+
+func TestModeTimingTemplates(t *testing.T) {
+	tests := []struct {
+		mode        Mode
+		workerCount int
+		timeout     time.Duration
+		delay       time.Duration
+		jitter      time.Duration
+		retries     int
+		hostTimeout time.Duration
+	}{
+		{ModeStealth, 10, 5 * time.Second, 250 * time.Millisecond, 250 * time.Millisecond, 0, 0},
+		{ModeParanoid, 1, 10 * time.Second, 5 * time.Minute, 1 * time.Minute, 2, 0},
+		{ModeSneaky, 1, 5 * time.Second, 15 * time.Second, 5 * time.Second, 2, 0},
+		{ModePolite, 10, 2 * time.Second, 400 * time.Millisecond, 100 * time.Millisecond, 2, 0},
+		{ModeNormal, 100, 1 * time.Second, 0, 0, 1, 0},
+		{ModeAggressive, 500, 500 * time.Millisecond, 0, 0, 1, 30 * time.Minute},
+		{ModeInsane, 1000, 250 * time.Millisecond, 0, 0, 0, 15 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.mode), func(t *testing.T) {
+			if got := tt.mode.WorkerCount(); got != tt.workerCount {
+				t.Errorf("%v.WorkerCount() = %d; want %d", tt.mode, got, tt.workerCount)
+			}
+			if got := tt.mode.Timeout(); got != tt.timeout {
+				t.Errorf("%v.Timeout() = %v; want %v", tt.mode, got, tt.timeout)
+			}
+			if got := tt.mode.Delay(); got != tt.delay {
+				t.Errorf("%v.Delay() = %v; want %v", tt.mode, got, tt.delay)
+			}
+			if got := tt.mode.Jitter(); got != tt.jitter {
+				t.Errorf("%v.Jitter() = %v; want %v", tt.mode, got, tt.jitter)
+			}
+			if got := tt.mode.Retries(); got != tt.retries {
+				t.Errorf("%v.Retries() = %d; want %d", tt.mode, got, tt.retries)
+			}
+			if got := tt.mode.HostTimeout(); got != tt.hostTimeout {
+				t.Errorf("%v.HostTimeout() = %v; want %v", tt.mode, got, tt.hostTimeout)
+			}
+		})
+	}
+}
@@ -0,0 +1,384 @@
+package scanner
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"port-scanner/internal/types"
+	"port-scanner/internal/utils"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/vbauerster/mpb"
+	"github.com/vbauerster/mpb/decor"
+)
+
+type Progress string
+
+const (
+	ProgressAuto  = "auto"
+	ProgressBar   = "bar"
+	ProgressPlain = "plain"
+	ProgressJson  = "json"
+	ProgressNone  = "none"
+)
+
+const (
+	progressInterval = 5 * time.Second
+	eventStart       = "start"
+	eventProgress    = "progress"
+	eventFinish      = "finish"
+	eventOpen        = "open"
+	escapeSequence   = "\x1b["
+)
+
+type Reporter interface {
+	Start(total int)
+	Increment(result types.Result)
+	Finish()
+}
+
+type counters struct {
+	start time.Time
+	total int64
+	done  atomic.Int64
+	open  atomic.Int64
+}
+
+type snapshot struct {
+	Event   string        `json:"event"`
+	Total   int64         `json:"total"`
+	Done    int64         `json:"done"`
+	Open    int64         `json:"open"`
+	Rate    float64       `json:"rate"`
+	Elapsed float64       `json:"elapsed_seconds"`
+	ETA     float64       `json:"eta_seconds"`
+	Result  *types.Result `json:"result,omitempty"`
+}
+
+type barReporter struct {
+	out      io.Writer
+	counters counters
+	live     *liveWriter
+	progress *mpb.Progress
+	bar      *mpb.Bar
+}
+
+type liveWriter struct {
+	mu      sync.Mutex
+	out     io.Writer
+	pending []string
+}
+
+type tickerReporter struct {
+	out      io.Writer
+	interval time.Duration
+	write    func(w io.Writer, s snapshot)
+	counters counters
+	mu       sync.Mutex
+	stop     chan struct{}
+	wg       sync.WaitGroup
+}
+
+type noopReporter struct{}
+
+type multiReporter []Reporter
+
+type openDecorator struct {
+	decor.WC
+	open *atomic.Int64
+}
+
+func ParseProgress(s string) (Progress, error) {
+	switch strings.ToLower(s) {
+	case "auto":
+		return ProgressAuto, nil
+	case "bar":
+		return ProgressBar, nil
+	case "plain":
+		return ProgressPlain, nil
+	case "json":
+		return ProgressJson, nil
+	case "none":
+		return ProgressNone, nil
+	default:
+		return "", fmt.Errorf("invalid progress: %q", s)
+	}
+}
+
+func NewReporter(progress Progress) Reporter {
+	switch progress {
+	case ProgressBar:
+		return &barReporter{out: os.Stderr}
+	case ProgressPlain:
+		return newTickerReporter(os.Stderr, progressInterval, writePlain)
+	case ProgressJson:
+		return newTickerReporter(os.Stderr, progressInterval, writeJSON)
+	case ProgressNone:
+		return noopReporter{}
+	default:
+		if utils.IsTerminal(os.Stderr) {
+			return NewReporter(ProgressBar)
+		}
+		return NewReporter(ProgressPlain)
+	}
+}
+
+func newReporter(cfg types.Config) Reporter {
+	if cfg.Quiet {
+		return NewReporter(ProgressNone)
+	}
+
+	progress, err := ParseProgress(cfg.Progress)
+	if err != nil {
+		progress = ProgressAuto
+	}
+
+	return NewReporter(progress)
+}
+
+func (c *counters) reset(total int) {
+	c.start = time.Now()
+	c.total = int64(total)
+	c.done.Store(0)
+	c.open.Store(0)
+}
+
+func (c *counters) add(result types.Result) {
+	c.done.Add(1)
+	if result.Status {
+		c.open.Add(1)
+	}
+}
+
+func (c *counters) snapshot(event string) snapshot {
+	s := snapshot{
+		Event:   event,
+		Total:   c.total,
+		Done:    c.done.Load(),
+		Open:    c.open.Load(),
+		Elapsed: time.Since(c.start).Seconds(),
+	}
+
+	if s.Elapsed > 0 {
+		s.Rate = float64(s.Done) / s.Elapsed
+	}
+	if s.Rate > 0 {
+		s.ETA = float64(s.Total-s.Done) / s.Rate
+	}
+
+	return s
+}
+
+func (r *barReporter) Start(total int) {
+	r.counters.reset(total)
+	r.live = &liveWriter{out: r.out}
+	r.progress, r.bar = buildProgressBar(total, r.live, &r.counters.open)
+}
+
+func (r *barReporter) Increment(result types.Result) {
+	r.counters.add(result)
+	if result.Status {
+		r.live.println(formatOpenPort(result))
+	}
+	r.bar.Increment()
+}
+
+func (r *barReporter) Finish() {
+	r.bar.SetTotal(r.counters.total, true)
+	r.progress.Wait()
+	r.live.flush()
+}
+
+func (w *liveWriter) println(line string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.pending = append(w.pending, line)
+}
+
+func (w *liveWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	n, err := w.out.Write(p)
+	if err != nil {
+		return n, err
+	}
+
+	if len(p) == 0 || bytes.HasPrefix(p, []byte(escapeSequence)) {
+		err = w.writePending()
+	}
+	return n, err
+}
+
+func (w *liveWriter) flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	_ = w.writePending()
+}
+
+func (w *liveWriter) writePending() error {
+	for _, line := range w.pending {
+		_, err := fmt.Fprintln(w.out, line)
+		if err != nil {
+			return err
+		}
+	}
+	w.pending = w.pending[:0]
+	return nil
+}
+
+func buildProgressBar(total int, out io.Writer, open *atomic.Int64) (*mpb.Progress, *mpb.Bar) {
+	p := mpb.New(mpb.WithWidth(60), mpb.WithOutput(out))
+	b := p.AddBar(int64(total),
+		mpb.PrependDecorators(
+			decor.Name("Scanning "),
+			decor.CountersNoUnit("%d / %d"),
+		),
+		mpb.AppendDecorators(
+			decor.Percentage(),
+			newOpenDecorator(open),
+			decor.AverageSpeed(0, " %.0f/s"),
+			decor.Name(" ETA "),
+			decor.AverageETA(decor.ET_STYLE_GO),
+		),
+	)
+	return p, b
+}
+
+func newOpenDecorator(open *atomic.Int64) decor.Decorator {
+	d := &openDecorator{open: open}
+	d.Init()
+	return d
+}
+
+func (d *openDecorator) Decor(_ *decor.Statistics) string {
+	return d.FormatMsg(fmt.Sprintf(" %d open", d.open.Load()))
+}
+
+func newTickerReporter(out io.Writer, interval time.Duration, write func(io.Writer, snapshot)) *tickerReporter {
+	return &tickerReporter{
+		out:      out,
+		interval: interval,
+		write:    write,
+	}
+}
+
+func (r *tickerReporter) Start(total int) {
+	r.counters.reset(total)
+	r.stop = make(chan struct{})
+	r.emit(r.counters.snapshot(eventStart))
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-r.stop:
+				return
+			case <-ticker.C:
+				r.emit(r.counters.snapshot(eventProgress))
+			}
+		}
+	}()
+}
+
+func (r *tickerReporter) Increment(result types.Result) {
+	r.counters.add(result)
+	if result.Status {
+		s := r.counters.snapshot(eventOpen)
+		s.Result = &result
+		r.emit(s)
+	}
+}
+
+func (r *tickerReporter) Finish() {
+	close(r.stop)
+	r.wg.Wait()
+	r.emit(r.counters.snapshot(eventFinish))
+}
+
+func (r *tickerReporter) emit(s snapshot) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.write(r.out, s)
+}
+
+func writePlain(w io.Writer, s snapshot) {
+	if s.Result != nil {
+		_, _ = fmt.Fprintln(w, formatOpenPort(*s.Result))
+		return
+	}
+
+	percentage := 100.0
+	if s.Total > 0 {
+		percentage = float64(s.Done) / float64(s.Total) * 100
+	}
+
+	eta := time.Duration(s.ETA * float64(time.Second)).Round(time.Second)
+	_, _ = fmt.Fprintf(w, "Scanning %d / %d (%.1f%%) %d open %.0f/s ETA %s\n",
+		s.Done, s.Total, percentage, s.Open, s.Rate, eta)
+}
+
+func writeJSON(w io.Writer, s snapshot) {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return
+	}
+	_, _ = fmt.Fprintln(w, string(data))
+}
+
+func formatOpenPort(result types.Result) string {
+	fields := []string{net.JoinHostPort(result.Host, strconv.Itoa(result.Port)), "open"}
+	if result.Service != "" {
+		fields = append(fields, result.Service)
+	}
+	if result.Banner != "" {
+		fields = append(fields, strconv.Quote(result.Banner))
+	}
+	return strings.Join(fields, " ")
+}
+
+func ScanReporter(cfg types.Config, reporters ...Reporter) Reporter {
+	return newMultiReporter(append([]Reporter{newReporter(cfg)}, reporters...))
+}
+
+func newMultiReporter(reporters []Reporter) Reporter {
+	if len(reporters) == 1 {
+		return reporters[0]
+	}
+	return multiReporter(reporters)
+}
+
+func (m multiReporter) Start(total int) {
+	for _, r := range m {
+		r.Start(total)
+	}
+}
+
+func (m multiReporter) Increment(result types.Result) {
+	for _, r := range m {
+		r.Increment(result)
+	}
+}
+
+func (m multiReporter) Finish() {
+	for _, r := range m {
+		r.Finish()
+	}
+}
+
+func (noopReporter) Start(int) {}
+
+func (noopReporter) Increment(types.Result) {}
+
+func (noopReporter) Finish() {}
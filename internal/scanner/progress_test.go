@@ -0,0 +1,312 @@
+package scanner
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"port-scanner/internal/types"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParseProgress(t *testing.T) {
+	tests := []struct {
+		input       string
+		expected    Progress
+		expectError bool
+	}{
+		{"auto", ProgressAuto, false},
+		{"bar", ProgressBar, false},
+		{"PLAIN", ProgressPlain, false},
+		{"json", ProgressJson, false},
+		{"none", ProgressNone, false},
+		{"fancy", "", true},
+		{"", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseProgress(tt.input)
+			if (err != nil) != tt.expectError {
+				t.Fatalf("ParseProgress(%q) error = %v, wantErr %v", tt.input, err, tt.expectError)
+			}
+			if got != tt.expected {
+				t.Errorf("ParseProgress(%q) = %v, want %v", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestNewReporter(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  types.Config
+		want string
+	}{
+		{"bar", types.Config{Progress: "bar"}, "*scanner.barReporter"},
+		{"plain", types.Config{Progress: "plain"}, "*scanner.tickerReporter"},
+		{"json", types.Config{Progress: "json"}, "*scanner.tickerReporter"},
+		{"none", types.Config{Progress: "none"}, "scanner.noopReporter"},
+		{"quiet overrides progress", types.Config{Progress: "bar", Quiet: true}, "scanner.noopReporter"},
+		{"auto without terminal", types.Config{Progress: "auto"}, "*scanner.tickerReporter"},
+		{"invalid falls back to auto", types.Config{Progress: "fancy"}, "*scanner.tickerReporter"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := fmt.Sprintf("%T", newReporter(tt.cfg))
+			if got != tt.want {
+				t.Errorf("newReporter() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildProgressBar(t *testing.T) {
+	tests := []struct {
+		name  string
+		total int
+	}{
+		{"empty port list", 0},
+		{"single port", 1},
+		{"multiple ports", 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var open atomic.Int64
+			p, b := buildProgressBar(tt.total, io.Discard, &open)
+
+			if p == nil {
+				t.Error("Progress should not be nil")
+			}
+
+			if b == nil {
+				t.Error("Bar should not be nil")
+			}
+		})
+	}
+}
+
+func TestBarReporter(t *testing.T) {
+	tests := []struct {
+		name    string
+		results []types.Result
+	}{
+		{"no tasks", nil},
+		{"some tasks", []types.Result{{Host: "10.0.0.1", Port: 80, Status: true}, {Host: "10.0.0.1", Port: 81}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			reporter := &barReporter{out: &out}
+			reporter.Start(len(tt.results))
+			for _, r := range tt.results {
+				reporter.Increment(r)
+			}
+
+			done := make(chan struct{})
+			go func() {
+				reporter.Finish()
+				close(done)
+			}()
+
+			select {
+			case <-done:
+			case <-time.After(5 * time.Second):
+				t.Fatal("Finish() did not return")
+			}
+
+			if !strings.Contains(out.String(), "Scanning") {
+				t.Errorf("barReporter output = %q, want progress bar", out.String())
+			}
+
+			for _, r := range tt.results {
+				line := formatOpenPort(r)
+				if strings.Contains(out.String(), line) != r.Status {
+					t.Errorf("barReporter output = %q, live line %q printed = %v", out.String(), line, !r.Status)
+				}
+			}
+		})
+	}
+}
+
+func TestTickerReporter(t *testing.T) {
+	tests := []struct {
+		name     string
+		write    func(io.Writer, snapshot)
+		contains []string
+	}{
+		{
+			name:     "plain",
+			write:    writePlain,
+			contains: []string{"Scanning 0 / 3 (0.0%) 0 open", "10.0.0.1:22 open ssh", "Scanning 3 / 3 (100.0%) 1 open"},
+		},
+		{
+			name:  "json",
+			write: writeJSON,
+			contains: []string{
+				`"event":"start"`,
+				`"event":"progress"`,
+				`"result":{"host":"10.0.0.1","port":22,"status":true,"service":"ssh"}`,
+				`"event":"finish","total":3,"done":3,"open":1`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			reporter := newTickerReporter(&out, 10*time.Millisecond, tt.write)
+			reporter.Start(3)
+			reporter.Increment(types.Result{Host: "10.0.0.1", Port: 22, Status: true, Service: "ssh"})
+			time.Sleep(30 * time.Millisecond)
+			reporter.Increment(types.Result{Port: 23})
+			reporter.Increment(types.Result{Port: 24})
+			reporter.Finish()
+
+			for _, want := range tt.contains {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("output missing %q in:\n%s", want, out.String())
+				}
+			}
+		})
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	var out bytes.Buffer
+	writeJSON(&out, snapshot{Event: eventProgress, Total: 10, Done: 5, Open: 2, Rate: 2.5, Elapsed: 2, ETA: 2})
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("writeJSON() wrote %d lines, want 1", len(lines))
+	}
+
+	var parsed snapshot
+	if err := json.Unmarshal([]byte(lines[0]), &parsed); err != nil {
+		t.Fatalf("writeJSON() produced invalid JSON: %v", err)
+	}
+	if parsed.Done != 5 || parsed.Open != 2 || parsed.ETA != 2 {
+		t.Errorf("writeJSON() = %+v", parsed)
+	}
+}
+
+func TestLiveWriter(t *testing.T) {
+	tests := []struct {
+		name     string
+		writes   []string
+		pending  []string
+		expected string
+	}{
+		{
+			name:     "no pending lines",
+			writes:   []string{"", "bar\n"},
+			expected: "bar\n",
+		},
+		{
+			name:     "pending lines are written before the first frame",
+			writes:   []string{"", "bar\n"},
+			pending:  []string{"10.0.0.1:22 open ssh"},
+			expected: "10.0.0.1:22 open ssh\nbar\n",
+		},
+		{
+			name:     "pending lines replace the cleared bar",
+			writes:   []string{"\x1b[1A\x1b[2K\r", "bar\n"},
+			pending:  []string{"10.0.0.1:22 open", "10.0.0.1:80 open http"},
+			expected: "\x1b[1A\x1b[2K\r10.0.0.1:22 open\n10.0.0.1:80 open http\nbar\n",
+		},
+		{
+			name:     "frames do not flush pending lines",
+			writes:   []string{"bar\n"},
+			pending:  []string{"10.0.0.1:22 open"},
+			expected: "bar\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			w := &liveWriter{out: &out}
+			for _, line := range tt.pending {
+				w.println(line)
+			}
+			for _, data := range tt.writes {
+				if _, err := w.Write([]byte(data)); err != nil {
+					t.Fatalf("Write() error = %v", err)
+				}
+			}
+
+			if out.String() != tt.expected {
+				t.Errorf("liveWriter output = %q, want %q", out.String(), tt.expected)
+			}
+		})
+	}
+}
+
+func TestFormatOpenPort(t *testing.T) {
+	tests := []struct {
+		name     string
+		result   types.Result
+		expected string
+	}{
+		{"port only", types.Result{Host: "10.0.0.1", Port: 9999, Status: true}, "10.0.0.1:9999 open"},
+		{"with service", types.Result{Host: "example.com", Port: 443, Status: true, Service: "https"}, "example.com:443 open https"},
+		{
+			name:     "with banner",
+			result:   types.Result{Host: "10.0.0.1", Port: 22, Status: true, Service: "ssh", Banner: "SSH-2.0-OpenSSH_9.6"},
+			expected: `10.0.0.1:22 open ssh "SSH-2.0-OpenSSH_9.6"`,
+		},
+		{"ipv6 host", types.Result{Host: "::1", Port: 80, Status: true, Service: "http"}, "[::1]:80 open http"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatOpenPort(tt.result); got != tt.expected {
+				t.Errorf("formatOpenPort() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+type recordingReporter struct {
+	events []string
+}
+
+func (r *recordingReporter) Start(total int) {
+	r.events = append(r.events, fmt.Sprintf("start %d", total))
+}
+
+func (r *recordingReporter) Increment(result types.Result) {
+	r.events = append(r.events, fmt.Sprintf("increment %d", result.Port))
+}
+
+func (r *recordingReporter) Finish() {
+	r.events = append(r.events, "finish")
+}
+
+func TestMultiReporter(t *testing.T) {
+	first, second := &recordingReporter{}, &recordingReporter{}
+
+	single := newMultiReporter([]Reporter{first})
+	if single != Reporter(first) {
+		t.Errorf("newMultiReporter() with one reporter = %T, want the reporter itself", single)
+	}
+
+	reporter := newMultiReporter([]Reporter{first, second})
+	reporter.Start(2)
+	reporter.Increment(types.Result{Port: 80})
+	reporter.Increment(types.Result{Port: 443})
+	reporter.Finish()
+
+	expected := []string{"start 2", "increment 80", "increment 443", "finish"}
+	for i, r := range []*recordingReporter{first, second} {
+		if strings.Join(r.events, ",") != strings.Join(expected, ",") {
+			t.Errorf("reporter %d events = %v, want %v", i, r.events, expected)
+		}
+	}
+}
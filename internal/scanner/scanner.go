@@ -1,17 +1,19 @@
 package scanner
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"math/rand/v2"
 	"net"
+	"net/netip"
 	"port-scanner/internal/types"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
-
-	"github.com/vbauerster/mpb"
-	"github.com/vbauerster/mpb/decor"
+	"unicode"
+	"unicode/utf8"
 )
 
 const (
@@ -20,33 +22,185 @@ const (
 	portRangeDelimiter = "-"
 	portListSeparator  = ","
 	networkTCP         = "tcp"
-	addressFormat      = "%s:%d"
+	maxBannerLength    = 256
+	taskBuffer         = 1024
+	maxProbes          = 1 << 24
 )
 
+type scanOptions struct {
+	timeout     time.Duration
+	delay       time.Duration
+	jitter      time.Duration
+	retries     int
+	hostTimeout time.Duration
+	workerCount int
+	banners     bool
+}
+
+type collector struct {
+	mu      sync.Mutex
+	results []types.Result
+}
+
+type hostDeadlines struct {
+	timeout   time.Duration
+	mu        sync.Mutex
+	deadlines map[string]time.Time
+}
+
+type hostAddresses struct {
+	resolve   func(host string) ([]net.IP, error)
+	mu        sync.Mutex
+	addresses map[string]*hostAddress
+}
+
+type hostAddress struct {
+	once    sync.Once
+	address string
+}
+
 var (
 	invalidPortFormatError = errors.New("invalid port format: expected range: '1-1024' or list: '80,443'")
 	invalidPortRangeError  = errors.New("invalid port range: expected range between 1 and 65535")
+	tooManyProbesError     = fmt.Errorf("too many probes: expected at most %d host and port combinations, split the scan with --shard", maxProbes)
 )
 
-func Scan(cfg types.Config) ([]types.Result, error) {
-	ports, err := parsePorts(cfg.Ports)
+func Scan(ctx context.Context, cfg types.Config, reporters ...Reporter) (types.Report, error) {
+	tasks, summary, metadata, err := Plan(ctx, cfg)
 	if err != nil {
-		return nil, err
+		return types.Report{}, err
+	}
+
+	opts := newScanOptions(scanMode(cfg), cfg)
+	start := time.Now()
+	results := scanPorts(ctx, tasks, summary.Scanned, opts, ScanReporter(cfg, reporters...))
+	if ctx.Err() != nil {
+		return types.Report{}, ctx.Err()
 	}
 
+	summary = Tally(summary, results)
+	metadata.Start = start
+	metadata.End = time.Now()
+
+	return types.Report{
+		Results:  results,
+		Summary:  summary,
+		Metadata: metadata,
+	}, nil
+}
+
+func Plan(ctx context.Context, cfg types.Config) (chan types.Task, types.Summary, types.Metadata, error) {
+	excl, err := newExclusions(cfg.Exclude, cfg.ExcludeFile, cfg.ExcludePorts)
+	if err != nil {
+		return nil, types.Summary{}, types.Metadata{}, err
+	}
+
+	tasks, summary, metadata, err := planScan(ctx, cfg, excl)
+	if err != nil {
+		return nil, types.Summary{}, types.Metadata{}, err
+	}
+
+	mode := scanMode(cfg)
+	opts := newScanOptions(mode, cfg)
+	metadata.Mode = string(mode)
+	metadata.Timeout = opts.timeout
+	metadata.Concurrency = opts.workerCount
+	return tasks, summary, metadata, nil
+}
+
+func ScanTasks(ctx context.Context, cfg types.Config, tasks []types.Task, reporters ...Reporter) ([]types.Result, error) {
+	queue := make(chan types.Task, len(tasks))
+	for i, task := range tasks {
+		queue <- types.Task{Index: i, Host: task.Host, Port: task.Port}
+	}
+	close(queue)
+
+	results := scanPorts(ctx, queue, len(tasks), newScanOptions(scanMode(cfg), cfg), newMultiReporter(reporters))
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	return results, nil
+}
+
+func Tally(summary types.Summary, results []types.Result) types.Summary {
+	for _, r := range results {
+		switch {
+		case r.Skipped:
+			summary.Scanned--
+			summary.Skipped++
+		case r.Status:
+			summary.Open++
+		}
+	}
+	return summary
+}
+
+func scanMode(cfg types.Config) Mode {
 	mode, err := ParseMode(cfg.Mode)
 	if err != nil {
-		mode = ModeDefault
+		return ModeDefault
+	}
+	return mode
+}
+
+func planScan(ctx context.Context, cfg types.Config, excl *exclusions) (chan types.Task, types.Summary, types.Metadata, error) {
+	shard, err := ParseShard(cfg.Shard, cfg.ShardSeed)
+	if err != nil {
+		return nil, types.Summary{}, types.Metadata{}, err
+	}
+
+	if cfg.InputResults != "" {
+		targets, err := loadInputTargets(cfg)
+		if err != nil {
+			return nil, types.Summary{}, types.Metadata{}, err
+		}
+
+		tasks, summary := createInputTasks(ctx, targets, excl, shard)
+		return tasks, summary, types.Metadata{Targets: inputHosts(targets), Input: cfg.InputResults, Shard: shard.String()}, nil
+	}
+
+	hosts, err := parseTargets(cfg.Address)
+	if err != nil {
+		return nil, types.Summary{}, types.Metadata{}, err
+	}
+
+	ports, err := parsePorts(cfg.Ports)
+	if err != nil {
+		return nil, types.Summary{}, types.Metadata{}, err
+	}
+
+	if probes := len(hosts) * len(ports); (probes+shard.step()-1)/shard.step() > maxProbes {
+		return nil, types.Summary{}, types.Metadata{}, tooManyProbesError
+	}
+
+	tasks, summary := createScanTasks(ctx, hosts, ports, excl, shard)
+	return tasks, summary, types.Metadata{Targets: splitTargets(cfg.Address), Ports: cfg.Ports, Shard: shard.String()}, nil
+}
+
+func newScanOptions(mode Mode, cfg types.Config) scanOptions {
+	opts := scanOptions{
+		timeout:     mode.Timeout(),
+		delay:       mode.Delay(),
+		jitter:      mode.Jitter(),
+		retries:     mode.Retries(),
+		hostTimeout: mode.HostTimeout(),
+		workerCount: mode.WorkerCount(),
+		banners:     cfg.Banners,
 	}
 
-	timeout := mode.Timeout()
 	if cfg.Timeout > 0 {
-		timeout = time.Duration(cfg.Timeout) * time.Millisecond
+		opts.timeout = time.Duration(cfg.Timeout) * time.Millisecond
 	}
 
-	results := scanPorts(cfg.Address, ports, timeout, mode.WorkerCount())
+	if cfg.ScanDelay >= 0 {
+		opts.delay = time.Duration(cfg.ScanDelay) * time.Millisecond
+	}
 
-	return results, nil
+	if cfg.MaxJitter >= 0 {
+		opts.jitter = time.Duration(cfg.MaxJitter) * time.Millisecond
+	}
+
+	return opts
 }
 
 func parsePorts(ports string) ([]int, error) {
@@ -106,80 +260,299 @@ func parsePortList(portList string) ([]int, error) {
 	return ports, nil
 }
 
-func scanPorts(host string, portList []int, timeout time.Duration, workerCount int) []types.Result {
-	tasks := createScanTasks(portList)
-	results := make([]types.Result, len(portList))
-	progress, bar := buildProgressBar(portList)
+func scanPorts(
+	ctx context.Context,
+	tasks chan types.Task,
+	total int,
+	opts scanOptions,
+	reporter Reporter,
+) []types.Result {
+	results := &collector{}
+	deadlines := newHostDeadlines(opts.hostTimeout)
+	addresses := newHostAddresses(net.LookupIP)
+	reporter.Start(total)
 
 	var wg sync.WaitGroup
-	startScanWorkers(tasks, results, host, timeout, workerCount, bar, &wg)
+	startScanWorkers(ctx, tasks, results, opts, deadlines, addresses, reporter, &wg)
 
 	wg.Wait()
-	progress.Wait()
-	return results
+	reporter.Finish()
+	return results.results
 }
 
-func createScanTasks(portList []int) chan types.Task {
-	tasks := make(chan types.Task, len(portList))
-	for i, port := range portList {
-		tasks <- types.Task{Index: i, Port: port}
+func createScanTasks(ctx context.Context, hosts []string, portList []int, excl *exclusions, shard Shard) (chan types.Task, types.Summary) {
+	if excl == nil {
+		excl = &exclusions{}
 	}
-	close(tasks)
-	return tasks
+
+	ports := make([]int, 0, len(portList))
+	allowed := make([]bool, len(portList))
+	residues := make(map[int]int)
+	for p, port := range portList {
+		if !excl.excludesPort(port) {
+			ports = append(ports, port)
+			allowed[p] = true
+			residues[p%shard.step()]++
+		}
+	}
+
+	included := make([]int, 0, len(hosts))
+	scanned := 0
+	for h, host := range hosts {
+		if !excl.excludesHost(host) {
+			included = append(included, h)
+			scanned += residues[shard.first(h*len(portList), host)]
+		}
+	}
+
+	summary := types.Summary{
+		Hosts:         len(included),
+		Ports:         len(ports),
+		Scanned:       scanned,
+		ExcludedHosts: len(hosts) - len(included),
+		ExcludedPorts: len(portList) - len(ports),
+	}
+
+	tasks := streamTasks(ctx, func(send func(host string, port int) bool) {
+		for _, h := range included {
+			for p := shard.first(h*len(portList), hosts[h]); p < len(portList); p += shard.step() {
+				if allowed[p] && !send(hosts[h], portList[p]) {
+					return
+				}
+			}
+		}
+	})
+	return tasks, summary
 }
 
-func buildProgressBar(portList []int) (*mpb.Progress, *mpb.Bar) {
-	p := mpb.New(mpb.WithWidth(60))
-	b := p.AddBar(int64(len(portList)),
-		mpb.PrependDecorators(
-			decor.Name("Scanning "),
-			decor.CountersNoUnit("%d / %d"),
-		),
-		mpb.AppendDecorators(decor.Percentage()),
-	)
-	return p, b
+func streamTasks(ctx context.Context, generate func(send func(host string, port int) bool)) chan types.Task {
+	tasks := make(chan types.Task, taskBuffer)
+	go func() {
+		defer close(tasks)
+		index := 0
+		generate(func(host string, port int) bool {
+			select {
+			case tasks <- types.Task{Index: index, Host: host, Port: port}:
+				index++
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		})
+	}()
+	return tasks
 }
 
 func startScanWorkers(
+	ctx context.Context,
 	tasks chan types.Task,
-	results []types.Result,
-	host string,
-	timeout time.Duration,
-	workerCount int,
-	bar *mpb.Bar,
+	results *collector,
+	opts scanOptions,
+	deadlines *hostDeadlines,
+	addresses *hostAddresses,
+	reporter Reporter,
 	wg *sync.WaitGroup,
 ) {
-	for i := 0; i < workerCount; i++ {
+	for i := 0; i < opts.workerCount; i++ {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			runScanWorker(tasks, results, host, timeout, bar)
+			runScanWorker(ctx, tasks, results, opts, deadlines, addresses, reporter)
 		}()
 	}
 }
 
 func runScanWorker(
+	ctx context.Context,
 	tasks chan types.Task,
-	results []types.Result,
-	host string,
-	timeout time.Duration,
-	bar *mpb.Bar,
+	results *collector,
+	opts scanOptions,
+	deadlines *hostDeadlines,
+	addresses *hostAddresses,
+	reporter Reporter,
 ) {
+	first := true
 	for task := range tasks {
-		open := scanPort(host, task.Port, timeout)
-		results[task.Index] = types.Result{Port: task.Port, Status: open}
-		bar.Increment()
+		result := types.Result{Host: task.Host, Port: task.Port}
+		if !first {
+			wait(ctx, probeDelay(opts))
+		}
+		probeCtx, cancel := deadlines.context(ctx, task.Host)
+		target := task.Host
+		if result.Address = addresses.lookup(task.Host); result.Address != "" {
+			target = result.Address
+		}
+		if probeCtx.Err() == nil {
+			started := time.Now()
+			result.Status = probePort(probeCtx, target, task.Port, opts)
+			if result.Status {
+				result.Latency = latency(time.Since(started))
+			}
+		}
+		result.Skipped = !result.Status && probeCtx.Err() != nil
+		if result.Status {
+			result.Service = serviceName(task.Port)
+		}
+		if result.Status && opts.banners {
+			result.Banner = grabBanner(probeCtx, target, task.Port, opts.timeout)
+		}
+		cancel()
+		first = false
+
+		results.add(task.Index, result)
+		reporter.Increment(result)
+	}
+}
+
+func (c *collector) add(index int, result types.Result) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for len(c.results) <= index {
+		c.results = append(c.results, types.Result{})
+	}
+	c.results[index] = result
+}
+
+func newHostDeadlines(timeout time.Duration) *hostDeadlines {
+	return &hostDeadlines{
+		timeout:   timeout,
+		deadlines: make(map[string]time.Time),
+	}
+}
+
+func (d *hostDeadlines) context(ctx context.Context, host string) (context.Context, context.CancelFunc) {
+	if d == nil || d.timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+
+	d.mu.Lock()
+	deadline, ok := d.deadlines[host]
+	if !ok {
+		deadline = time.Now().Add(d.timeout)
+		d.deadlines[host] = deadline
+	}
+	d.mu.Unlock()
+
+	return context.WithDeadline(ctx, deadline)
+}
+
+func newHostAddresses(resolve func(host string) ([]net.IP, error)) *hostAddresses {
+	return &hostAddresses{
+		resolve:   resolve,
+		addresses: make(map[string]*hostAddress),
+	}
+}
+
+func (a *hostAddresses) lookup(host string) string {
+	if a == nil {
+		return ""
+	}
+	if _, err := netip.ParseAddr(host); err == nil {
+		return ""
+	}
+
+	a.mu.Lock()
+	h, ok := a.addresses[host]
+	if !ok {
+		h = &hostAddress{}
+		a.addresses[host] = h
+	}
+	a.mu.Unlock()
+
+	h.once.Do(func() {
+		ips, err := a.resolve(host)
+		if err != nil || len(ips) == 0 {
+			return
+		}
+		if addr, ok := netip.AddrFromSlice(ips[0]); ok {
+			h.address = addr.Unmap().String()
+		}
+	})
+	return h.address
+}
+
+func probeDelay(opts scanOptions) time.Duration {
+	if opts.jitter <= 0 {
+		return opts.delay
+	}
+	return opts.delay + rand.N(opts.jitter+1)
+}
+
+func wait(ctx context.Context, d time.Duration) {
+	if d <= 0 {
+		return
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
 	}
 }
 
+func probePort(ctx context.Context, host string, port int, opts scanOptions) bool {
+	for attempt := 0; ; attempt++ {
+		err := dialPort(ctx, host, port, opts.timeout)
+		if err == nil {
+			return true
+		}
+
+		var netErr net.Error
+		if attempt >= opts.retries || ctx.Err() != nil || !errors.As(err, &netErr) || !netErr.Timeout() {
+			return false
+		}
+	}
+}
+
+func latency(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
 func scanPort(host string, port int, timeout time.Duration) bool {
-	address := fmt.Sprintf(addressFormat, host, port)
-	conn, err := net.DialTimeout(networkTCP, address, timeout)
+	return dialPort(context.Background(), host, port, timeout) == nil
+}
+
+func grabBanner(ctx context.Context, host string, port int, timeout time.Duration) string {
+	dialer := net.Dialer{Timeout: timeout}
+	conn, err := dialer.DialContext(ctx, networkTCP, net.JoinHostPort(host, strconv.Itoa(port)))
+	if err != nil {
+		return ""
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	err = conn.SetReadDeadline(time.Now().Add(timeout))
+	if err != nil {
+		return ""
+	}
+
+	buf := make([]byte, maxBannerLength)
+	n, _ := conn.Read(buf)
+	return sanitizeBanner(buf[:n])
+}
+
+func sanitizeBanner(data []byte) string {
+	line, _, _ := strings.Cut(string(data), "\n")
+	return strings.TrimSpace(strings.Map(func(r rune) rune {
+		if r == utf8.RuneError || !unicode.IsPrint(r) {
+			return -1
+		}
+		return r
+	}, line))
+}
+
+func dialPort(ctx context.Context, host string, port int, timeout time.Duration) error {
+	dialer := net.Dialer{Timeout: timeout}
+	conn, err := dialer.DialContext(ctx, networkTCP, net.JoinHostPort(host, strconv.Itoa(port)))
 	if err != nil {
-		return false
+		return err
 	}
 	defer func() {
 		_ = conn.Close()
 	}()
-	return true
+	return nil
 }
@@ -1,15 +1,16 @@
 package scanner
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"io"
 	"net"
 	"port-scanner/internal/types"
+	"reflect"
 	"sync"
 	"testing"
 	"time"
-
-	"github.com/vbauerster/mpb"
 )
 
 func TestScan(t *testing.T) {
@@ -71,7 +72,7 @@ func TestScan(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			results, err := Scan(tt.config)
+			report, err := Scan(context.Background(), tt.config)
 
 			if tt.expectErr {
 				if err == nil {
@@ -85,18 +86,96 @@ func TestScan(t *testing.T) {
 				return
 			}
 
-			if results == nil {
+			if report.Results == nil {
 				t.Error("Expected results but got nil")
 				return
 			}
 
-			if len(results) == 0 {
+			if len(report.Results) == 0 {
 				t.Error("Expected at least one result")
 			}
 		})
 	}
 }
 
+func TestScanMetadata(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   types.Config
+		expected types.Metadata
+	}{
+		{
+			name:   "mode defaults",
+			config: types.Config{Address: "127.0.0.1, localhost", Ports: "1", Mode: "rapid", Progress: "none"},
+			expected: types.Metadata{
+				Targets:     []string{"127.0.0.1", "localhost"},
+				Ports:       "1",
+				Mode:        ModeRapid,
+				Timeout:     500 * time.Millisecond,
+				Concurrency: 1000,
+			},
+		},
+		{
+			name:   "timeout override and invalid mode",
+			config: types.Config{Address: "127.0.0.1", Ports: "1-2", Mode: "invalid", Timeout: 50, Progress: "none"},
+			expected: types.Metadata{
+				Targets:     []string{"127.0.0.1"},
+				Ports:       "1-2",
+				Mode:        ModeDefault,
+				Timeout:     50 * time.Millisecond,
+				Concurrency: 100,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := time.Now()
+			report, err := Scan(context.Background(), tt.config)
+			if err != nil {
+				t.Fatalf("Scan() error = %v", err)
+			}
+
+			got := report.Metadata
+			if got.Start.Before(before) || got.End.Before(got.Start) {
+				t.Errorf("Scan() start = %v, end = %v, want both after %v", got.Start, got.End, before)
+			}
+
+			got.Start, got.End = time.Time{}, time.Time{}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Scan() metadata = %+v, want %+v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPlanTooManyProbes(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     types.Config
+		wantErr bool
+	}{
+		{"every port of a /24", types.Config{Address: "10.0.0.0/24", Ports: "1-65535"}, false},
+		{"every port of a /16", types.Config{Address: "10.0.0.0/16", Ports: "1-65535"}, true},
+		{"every port of a /16 in shards", types.Config{Address: "10.0.0.0/16", Ports: "1-65535", Shard: "1/256"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			_, _, _, err := Plan(ctx, tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Plan() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, tooManyProbesError) {
+				t.Errorf("Plan() error = %v, want %v", err, tooManyProbesError)
+			}
+		})
+	}
+}
+
 func TestParsePorts(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -414,7 +493,8 @@ func TestScanPorts(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			results := scanPorts("127.0.0.1", tt.portList, time.Millisecond*100, tt.workerCount)
+			tasks, _ := createScanTasks(context.Background(), []string{"127.0.0.1"}, tt.portList, nil, Shard{})
+			results := scanPorts(context.Background(), tasks, len(tt.portList), scanOptions{timeout: time.Millisecond * 100, workerCount: tt.workerCount}, noopReporter{})
 
 			if len(results) != len(tt.portList) {
 				t.Errorf("Expected %d results, got %d", len(tt.portList), len(results))
@@ -455,7 +535,7 @@ func TestCreateScanTasks(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			tasks := createScanTasks(tt.portList)
+			tasks, summary := createScanTasks(context.Background(), []string{"127.0.0.1"}, tt.portList, nil, Shard{})
 
 			if tasks == nil {
 				t.Error("Tasks channel should not be nil")
@@ -470,6 +550,10 @@ func TestCreateScanTasks(t *testing.T) {
 				t.Errorf("Expected %d tasks, got %d", len(tt.portList), len(receivedTasks))
 			}
 
+			if summary.Scanned != len(tt.portList) {
+				t.Errorf("Summary.Scanned = %d, want %d", summary.Scanned, len(tt.portList))
+			}
+
 			for i, expectedPort := range tt.portList {
 				if i < len(receivedTasks) {
 					if receivedTasks[i].Index != i {
@@ -478,48 +562,155 @@ func TestCreateScanTasks(t *testing.T) {
 					if receivedTasks[i].Port != expectedPort {
 						t.Errorf("Task[%d].Port = %d, want %d", i, receivedTasks[i].Port, expectedPort)
 					}
+					if receivedTasks[i].Host != "127.0.0.1" {
+						t.Errorf("Task[%d].Host = %q, want %q", i, receivedTasks[i].Host, "127.0.0.1")
+					}
 				}
 			}
 		})
 	}
 }
 
-func TestBuildProgressBar(t *testing.T) {
+func TestCreateScanTasksExclusions(t *testing.T) {
+	excl, err := newExclusions([]string{"10.0.0.2"}, "", "22,3306")
+	if err != nil {
+		t.Fatalf("newExclusions() unexpected error: %v", err)
+	}
+
+	hosts := []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"}
+	ports := []int{22, 80, 443, 3306}
+
+	tasks, summary := createScanTasks(context.Background(), hosts, ports, excl, Shard{})
+
+	expected := types.Summary{Hosts: 2, Ports: 2, Scanned: 4, ExcludedHosts: 1, ExcludedPorts: 2}
+	if summary != expected {
+		t.Errorf("Summary = %+v, want %+v", summary, expected)
+	}
+
+	expectedTasks := []types.Task{
+		{Index: 0, Host: "10.0.0.1", Port: 80},
+		{Index: 1, Host: "10.0.0.1", Port: 443},
+		{Index: 2, Host: "10.0.0.3", Port: 80},
+		{Index: 3, Host: "10.0.0.3", Port: 443},
+	}
+
+	var received []types.Task
+	for task := range tasks {
+		received = append(received, task)
+	}
+
+	if !reflect.DeepEqual(received, expectedTasks) {
+		t.Errorf("Tasks = %+v, want %+v", received, expectedTasks)
+	}
+}
+
+func TestCreateScanTasksStreams(t *testing.T) {
+	hosts := make([]string, 256)
+	for i := range hosts {
+		hosts[i] = fmt.Sprintf("10.0.%d.%d", i/256, i%256)
+	}
+	ports := make([]int, 1024)
+	for i := range ports {
+		ports[i] = i + 1
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	tasks, summary := createScanTasks(ctx, hosts, ports, nil, Shard{})
+
+	if summary.Scanned != len(hosts)*len(ports) {
+		t.Errorf("Summary.Scanned = %d, want %d", summary.Scanned, len(hosts)*len(ports))
+	}
+	if cap(tasks) != taskBuffer {
+		t.Errorf("cap(tasks) = %d, want %d", cap(tasks), taskBuffer)
+	}
+
+	if task := <-tasks; task != (types.Task{Index: 0, Host: "10.0.0.0", Port: 1}) {
+		t.Errorf("first task = %+v, want 10.0.0.0:1", task)
+	}
+	cancel()
+
+	received := 1
+	for range tasks {
+		received++
+	}
+	if received >= summary.Scanned {
+		t.Errorf("received %d tasks after cancel, want the producer to stop early", received)
+	}
+}
+
+func TestHostDeadlines(t *testing.T) {
+	deadlines := newHostDeadlines(50 * time.Millisecond)
+
+	first, cancel := deadlines.context(context.Background(), "10.0.0.1")
+	defer cancel()
+	if first.Err() != nil {
+		t.Error("context() on first probe is done, want it active")
+	}
+
+	time.Sleep(60 * time.Millisecond)
+
+	if first.Err() == nil {
+		t.Error("context() in flight after host timeout is active, want it done")
+	}
+	later, cancel := deadlines.context(context.Background(), "10.0.0.1")
+	defer cancel()
+	if later.Err() == nil {
+		t.Error("context() after host timeout is active, want it done")
+	}
+
+	other, cancel := deadlines.context(context.Background(), "10.0.0.2")
+	defer cancel()
+	if other.Err() != nil {
+		t.Error("context() for other host is done, want it active")
+	}
+
+	var disabled *hostDeadlines
+	unlimited, cancel := disabled.context(context.Background(), "10.0.0.1")
+	defer cancel()
+	if _, ok := unlimited.Deadline(); ok || unlimited.Err() != nil {
+		t.Error("context() without deadlines has a deadline, want none")
+	}
+}
+
+func TestHostAddresses(t *testing.T) {
+	calls := make(map[string]int)
+	addresses := newHostAddresses(func(host string) ([]net.IP, error) {
+		calls[host]++
+		if host == "example.com" {
+			return []net.IP{net.ParseIP("93.184.216.34")}, nil
+		}
+		return nil, errors.New("no such host")
+	})
+
 	tests := []struct {
 		name     string
-		portList []int
-		expected int64
+		host     string
+		expected string
 	}{
-		{
-			name:     "empty port list",
-			portList: []int{},
-			expected: 0,
-		},
-		{
-			name:     "single port",
-			portList: []int{80},
-			expected: 1,
-		},
-		{
-			name:     "multiple ports",
-			portList: []int{80, 443, 22, 21, 25},
-			expected: 5,
-		},
+		{"ipv4 literal", "10.0.0.1", ""},
+		{"ipv6 literal", "::1", ""},
+		{"hostname", "example.com", "93.184.216.34"},
+		{"hostname again", "example.com", "93.184.216.34"},
+		{"unresolved hostname", "db.internal", ""},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			p, b := buildProgressBar(tt.portList)
-
-			if p == nil {
-				t.Error("Progress should not be nil")
-			}
-
-			if b == nil {
-				t.Error("Bar should not be nil")
+			if got := addresses.lookup(tt.host); got != tt.expected {
+				t.Errorf("lookup(%q) = %q, want %q", tt.host, got, tt.expected)
 			}
 		})
 	}
+
+	expected := map[string]int{"example.com": 1, "db.internal": 1}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Errorf("resolve calls = %v, want %v", calls, expected)
+	}
+
+	var disabled *hostAddresses
+	if got := disabled.lookup("example.com"); got != "" {
+		t.Errorf("lookup() without addresses = %q, want empty", got)
+	}
 }
 
 func TestStartScanWorkers(t *testing.T) {
@@ -533,21 +724,21 @@ func TestStartScanWorkers(t *testing.T) {
 	openPort := listener.Addr().(*net.TCPAddr).Port
 
 	testTasks := []types.Task{
-		{Port: openPort, Index: 0},
-		{Port: 99999, Index: 1},
-		{Port: openPort, Index: 2},
-		{Port: 99998, Index: 3},
+		{Host: "127.0.0.1", Port: openPort, Index: 0},
+		{Host: "127.0.0.1", Port: 99999, Index: 1},
+		{Host: "127.0.0.1", Port: openPort, Index: 2},
+		{Host: "127.0.0.1", Port: 99998, Index: 3},
 	}
 
 	tasks := make(chan types.Task, len(testTasks))
-	results := make([]types.Result, len(testTasks))
+	results := &collector{}
 	var wg sync.WaitGroup
 
-	p := mpb.New()
-	bar := p.AddBar(int64(len(testTasks)))
+	reporter := &barReporter{out: io.Discard}
+	reporter.Start(len(testTasks))
 
-	workerCount := 3
-	startScanWorkers(tasks, results, "127.0.0.1", time.Millisecond*100, workerCount, bar, &wg)
+	opts := scanOptions{timeout: time.Millisecond * 100, workerCount: 3}
+	startScanWorkers(context.Background(), tasks, results, opts, nil, nil, reporter, &wg)
 
 	for _, task := range testTasks {
 		tasks <- task
@@ -555,15 +746,15 @@ func TestStartScanWorkers(t *testing.T) {
 	close(tasks)
 
 	wg.Wait()
-	p.Wait()
+	reporter.Finish()
 
 	expectedStatuses := []bool{true, false, true, false}
 	for i, expected := range expectedStatuses {
-		if results[i].Status != expected {
-			t.Errorf("Result[%d].Status = %v, want %v", i, results[i].Status, expected)
+		if results.results[i].Status != expected {
+			t.Errorf("Result[%d].Status = %v, want %v", i, results.results[i].Status, expected)
 		}
-		if results[i].Port != testTasks[i].Port {
-			t.Errorf("Result[%d].Port = %d, want %d", i, results[i].Port, testTasks[i].Port)
+		if results.results[i].Port != testTasks[i].Port {
+			t.Errorf("Result[%d].Port = %d, want %d", i, results.results[i].Port, testTasks[i].Port)
 		}
 	}
 }
@@ -579,25 +770,25 @@ func TestRunScanWorker(t *testing.T) {
 	openPort := listener.Addr().(*net.TCPAddr).Port
 
 	testTasks := []types.Task{
-		{Port: openPort, Index: 0},
-		{Port: 99999, Index: 1},
-		{Port: openPort, Index: 2},
+		{Host: "127.0.0.1", Port: openPort, Index: 0},
+		{Host: "127.0.0.1", Port: 99999, Index: 1},
+		{Host: "127.0.0.1", Port: openPort, Index: 2},
 	}
 
 	tasks := make(chan types.Task, len(testTasks))
-	results := make([]types.Result, len(testTasks))
+	results := &collector{}
 
-	p := mpb.New()
-	bar := p.AddBar(int64(len(testTasks)))
+	reporter := &barReporter{out: io.Discard}
+	reporter.Start(len(testTasks))
 
 	for _, task := range testTasks {
 		tasks <- task
 	}
 	close(tasks)
 
-	runScanWorker(tasks, results, "127.0.0.1", time.Millisecond*100, bar)
+	runScanWorker(context.Background(), tasks, results, scanOptions{timeout: time.Millisecond * 100}, nil, nil, reporter)
 
-	p.Wait()
+	reporter.Finish()
 
 	expectedResults := []struct {
 		port   int
@@ -609,12 +800,229 @@ func TestRunScanWorker(t *testing.T) {
 	}
 
 	for i, expected := range expectedResults {
-		if results[i].Port != expected.port {
-			t.Errorf("Result[%d].Port = %d, want %d", i, results[i].Port, expected.port)
+		if results.results[i].Port != expected.port {
+			t.Errorf("Result[%d].Port = %d, want %d", i, results.results[i].Port, expected.port)
 		}
-		if results[i].Status != expected.status {
-			t.Errorf("Result[%d].Status = %v, want %v", i, results[i].Status, expected.status)
+		if results.results[i].Status != expected.status {
+			t.Errorf("Result[%d].Status = %v, want %v", i, results.results[i].Status, expected.status)
 		}
+		if (results.results[i].Latency > 0) != expected.status {
+			t.Errorf("Result[%d].Latency = %v, want latency for open ports only", i, results.results[i].Latency)
+		}
+	}
+}
+
+func TestRunScanWorkerDelay(t *testing.T) {
+	testTasks := []types.Task{
+		{Host: "127.0.0.1", Port: 99999, Index: 0},
+		{Host: "127.0.0.1", Port: 99998, Index: 1},
+		{Host: "127.0.0.1", Port: 99997, Index: 2},
+	}
+
+	tasks := make(chan types.Task, len(testTasks))
+	results := &collector{}
+	for _, task := range testTasks {
+		tasks <- task
+	}
+	close(tasks)
+
+	reporter := &barReporter{out: io.Discard}
+	reporter.Start(len(testTasks))
+
+	delay := 50 * time.Millisecond
+	start := time.Now()
+	runScanWorker(context.Background(), tasks, results, scanOptions{timeout: time.Millisecond * 100, delay: delay}, nil, nil, reporter)
+	reporter.Finish()
+
+	if elapsed := time.Since(start); elapsed < 2*delay {
+		t.Errorf("runScanWorker() took %v, want at least %v", elapsed, 2*delay)
+	}
+}
+
+func TestRunScanWorkerHostTimeout(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to create test listener: %v", err)
+	}
+	defer func(listener net.Listener) {
+		_ = listener.Close()
+	}(listener)
+	openPort := listener.Addr().(*net.TCPAddr).Port
+
+	tasks := make(chan types.Task, 1)
+	results := &collector{}
+	tasks <- types.Task{Host: "127.0.0.1", Port: openPort, Index: 0}
+	close(tasks)
+
+	reporter := &barReporter{out: io.Discard}
+	reporter.Start(1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	runScanWorker(ctx, tasks, results, scanOptions{timeout: time.Millisecond * 100}, nil, nil, reporter)
+	reporter.Finish()
+
+	if results.results[0].Port != openPort || results.results[0].Status || !results.results[0].Skipped {
+		t.Errorf("Result[0] = %+v, want port %d skipped", results.results[0], openPort)
+	}
+}
+
+func TestRunScanWorkerSkipsAfterHostTimeout(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to create test listener: %v", err)
+	}
+	defer func(listener net.Listener) {
+		_ = listener.Close()
+	}(listener)
+	openPort := listener.Addr().(*net.TCPAddr).Port
+
+	testTasks := []types.Task{
+		{Host: "127.0.0.1", Port: openPort, Index: 0},
+		{Host: "127.0.0.1", Port: openPort, Index: 1},
+	}
+
+	tasks := make(chan types.Task, len(testTasks))
+	results := &collector{}
+	for _, task := range testTasks {
+		tasks <- task
+	}
+	close(tasks)
+
+	reporter := &barReporter{out: io.Discard}
+	reporter.Start(len(testTasks))
+
+	deadlines := newHostDeadlines(20 * time.Millisecond)
+	opts := scanOptions{timeout: time.Millisecond * 100, delay: 50 * time.Millisecond}
+	runScanWorker(context.Background(), tasks, results, opts, deadlines, nil, reporter)
+	reporter.Finish()
+
+	if !results.results[0].Status || results.results[0].Skipped {
+		t.Errorf("Result[0] = %+v, want open before the host timeout", results.results[0])
+	}
+	if results.results[1].Status || !results.results[1].Skipped {
+		t.Errorf("Result[1] = %+v, want skipped after the host timeout", results.results[1])
+	}
+
+	summary := Tally(types.Summary{Scanned: 2}, results.results)
+	if summary != (types.Summary{Scanned: 1, Open: 1, Skipped: 1}) {
+		t.Errorf("Tally() = %+v, want the skipped probe left out", summary)
+	}
+}
+
+func TestNewScanOptions(t *testing.T) {
+	tests := []struct {
+		name     string
+		mode     Mode
+		cfg      types.Config
+		expected scanOptions
+	}{
+		{
+			name: "mode values",
+			mode: ModePolite,
+			cfg:  types.Config{ScanDelay: -1, MaxJitter: -1},
+			expected: scanOptions{
+				timeout:     2 * time.Second,
+				delay:       400 * time.Millisecond,
+				jitter:      100 * time.Millisecond,
+				retries:     2,
+				workerCount: 10,
+			},
+		},
+		{
+			name: "delay and jitter override",
+			mode: ModeStealth,
+			cfg:  types.Config{ScanDelay: 2000, MaxJitter: 1000},
+			expected: scanOptions{
+				timeout:     5 * time.Second,
+				delay:       2 * time.Second,
+				jitter:      1 * time.Second,
+				workerCount: 10,
+			},
+		},
+		{
+			name: "zero delay and jitter override",
+			mode: ModeStealth,
+			cfg:  types.Config{ScanDelay: 0, MaxJitter: 0},
+			expected: scanOptions{
+				timeout:     5 * time.Second,
+				workerCount: 10,
+			},
+		},
+		{
+			name: "timeout override",
+			mode: ModeInsane,
+			cfg:  types.Config{Timeout: 750},
+			expected: scanOptions{
+				timeout:     750 * time.Millisecond,
+				hostTimeout: 15 * time.Minute,
+				workerCount: 1000,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newScanOptions(tt.mode, tt.cfg); got != tt.expected {
+				t.Errorf("newScanOptions() = %+v, want %+v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestProbeDelay(t *testing.T) {
+	tests := []struct {
+		name string
+		opts scanOptions
+		min  time.Duration
+		max  time.Duration
+	}{
+		{"no delay", scanOptions{}, 0, 0},
+		{"delay without jitter", scanOptions{delay: time.Second}, time.Second, time.Second},
+		{"delay with jitter", scanOptions{delay: time.Second, jitter: 500 * time.Millisecond}, time.Second, 1500 * time.Millisecond},
+		{"jitter without delay", scanOptions{jitter: 200 * time.Millisecond}, 0, 200 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 100; i++ {
+				got := probeDelay(tt.opts)
+				if got < tt.min || got > tt.max {
+					t.Fatalf("probeDelay() = %v, want between %v and %v", got, tt.min, tt.max)
+				}
+			}
+		})
+	}
+}
+
+func TestProbePort(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to create test listener: %v", err)
+	}
+	defer func(listener net.Listener) {
+		_ = listener.Close()
+	}(listener)
+	openPort := listener.Addr().(*net.TCPAddr).Port
+
+	tests := []struct {
+		name     string
+		port     int
+		retries  int
+		expected bool
+	}{
+		{"open port", openPort, 0, true},
+		{"closed port is not retried", 99999, 3, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := probePort(context.Background(), "127.0.0.1", tt.port, scanOptions{timeout: time.Millisecond * 100, retries: tt.retries})
+			if got != tt.expected {
+				t.Errorf("probePort() = %v, want %v", got, tt.expected)
+			}
+		})
 	}
 }
 
@@ -668,3 +1076,53 @@ func TestScanPort(t *testing.T) {
 		})
 	}
 }
+
+func TestGrabBanner(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to create test listener: %v", err)
+	}
+	defer func(listener net.Listener) {
+		_ = listener.Close()
+	}(listener)
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		_, _ = conn.Write([]byte("SSH-2.0-OpenSSH_9.6\r\n"))
+		_ = conn.Close()
+	}()
+
+	got := grabBanner(context.Background(), "127.0.0.1", port, time.Second)
+	if got != "SSH-2.0-OpenSSH_9.6" {
+		t.Errorf("grabBanner() = %q, want %q", got, "SSH-2.0-OpenSSH_9.6")
+	}
+
+	if got := grabBanner(context.Background(), "127.0.0.1", 99999, time.Millisecond*100); got != "" {
+		t.Errorf("grabBanner() on closed port = %q, want empty", got)
+	}
+}
+
+func TestSanitizeBanner(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []byte
+		expected string
+	}{
+		{"empty", []byte{}, ""},
+		{"first line only", []byte("220 mail ESMTP\r\n250 ok\r\n"), "220 mail ESMTP"},
+		{"control characters removed", []byte("\x00\x01hello\x7f world\t"), "hello world"},
+		{"invalid utf8 removed", []byte("abc\xffdef"), "abcdef"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sanitizeBanner(tt.input); got != tt.expected {
+				t.Errorf("sanitizeBanner() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
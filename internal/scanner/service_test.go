@@ -0,0 +1,26 @@
+package scanner
+
+import "testing"
+
+func TestServiceName(t *testing.T) {
+	tests := []struct {
+		port     int
+		expected string
+	}{
+		{22, "ssh"},
+		{80, "http"},
+		{443, "https"},
+		{5432, "postgresql"},
+		{8080, "http-proxy"},
+		{1, ""},
+		{65535, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			if got := serviceName(tt.port); got != tt.expected {
+				t.Errorf("serviceName(%d) = %q, want %q", tt.port, got, tt.expected)
+			}
+		})
+	}
+}
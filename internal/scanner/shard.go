@@ -0,0 +1,85 @@
+package scanner
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
+)
+
+const (
+	shardSeparator = "/"
+)
+
+var (
+	invalidShardError = errors.New("invalid shard: expected i/n with 1 <= i <= n, e.g. 2/4")
+)
+
+type Shard struct {
+	Index int
+	Count int
+	Seed  uint64
+}
+
+func ParseShard(s string, seed int) (Shard, error) {
+	if strings.TrimSpace(s) == "" {
+		return Shard{}, nil
+	}
+
+	index, count, ok := strings.Cut(s, shardSeparator)
+	if !ok {
+		return Shard{}, fmt.Errorf("%w: %q", invalidShardError, s)
+	}
+
+	i, err := strconv.Atoi(strings.TrimSpace(index))
+	if err != nil {
+		return Shard{}, fmt.Errorf("%w: %q", invalidShardError, s)
+	}
+
+	n, err := strconv.Atoi(strings.TrimSpace(count))
+	if err != nil || n < 1 || i < 1 || i > n {
+		return Shard{}, fmt.Errorf("%w: %q", invalidShardError, s)
+	}
+
+	return Shard{Index: i, Count: n, Seed: uint64(seed)}, nil
+}
+
+func (s Shard) String() string {
+	if s.Count == 0 {
+		return ""
+	}
+	return strconv.Itoa(s.Index) + shardSeparator + strconv.Itoa(s.Count)
+}
+
+func (s Shard) includes(position int, host string) bool {
+	return s.first(position, host) == 0
+}
+
+func (s Shard) first(position int, host string) int {
+	if s.Count <= 1 {
+		return 0
+	}
+	return ((s.Index-1-position-s.offset(host))%s.Count + s.Count) % s.Count
+}
+
+func (s Shard) step() int {
+	if s.Count <= 1 {
+		return 1
+	}
+	return s.Count
+}
+
+func (s Shard) offset(host string) int {
+	if s.Count <= 1 || s.Seed == 0 {
+		return 0
+	}
+
+	h := fnv.New64a()
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, s.Seed)
+	_, _ = h.Write(buf)
+	_, _ = h.Write([]byte(host))
+	return int(h.Sum64() % uint64(s.Count))
+}
@@ -0,0 +1,145 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"port-scanner/internal/types"
+	"reflect"
+	"testing"
+)
+
+func TestParseShard(t *testing.T) {
+	tests := []struct {
+		input    string
+		seed     int
+		expected Shard
+		wantErr  bool
+	}{
+		{"", 0, Shard{}, false},
+		{"1/1", 0, Shard{Index: 1, Count: 1}, false},
+		{"2/4", 0, Shard{Index: 2, Count: 4}, false},
+		{" 3 / 4 ", 7, Shard{Index: 3, Count: 4, Seed: 7}, false},
+		{"0/4", 0, Shard{}, true},
+		{"5/4", 0, Shard{}, true},
+		{"1/0", 0, Shard{}, true},
+		{"2", 0, Shard{}, true},
+		{"a/b", 0, Shard{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseShard(tt.input, tt.seed)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseShard() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, invalidShardError) {
+				t.Errorf("ParseShard() error = %v, want %v", err, invalidShardError)
+			}
+			if got != tt.expected {
+				t.Errorf("ParseShard() = %+v, want %+v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCreateScanTasksShards(t *testing.T) {
+	hosts := []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"}
+	ports := []int{22, 80, 443, 3306, 8080}
+
+	tests := []struct {
+		name  string
+		count int
+		seed  uint64
+	}{
+		{"round robin", 4, 0},
+		{"seeded", 3, 42},
+		{"seeded uneven", 4, 7},
+		{"single shard", 1, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			excl, err := newExclusions(nil, "", "3306")
+			if err != nil {
+				t.Fatalf("newExclusions() unexpected error: %v", err)
+			}
+
+			seen := make(map[types.Task]int)
+			scanned := 0
+			for i := 1; i <= tt.count; i++ {
+				shard := Shard{Index: i, Count: tt.count, Seed: tt.seed}
+				tasks, summary := createScanTasks(context.Background(), hosts, ports, excl, shard)
+				again, _ := createScanTasks(context.Background(), hosts, ports, excl, shard)
+
+				index := 0
+				for task := range tasks {
+					if task.Index != index {
+						t.Errorf("shard %d task index = %d, want %d", i, task.Index, index)
+					}
+					if repeat := <-again; repeat != task {
+						t.Errorf("shard %d is not deterministic: %+v then %+v", i, task, repeat)
+					}
+					seen[types.Task{Host: task.Host, Port: task.Port}]++
+					index++
+				}
+				if summary.Scanned != index || summary.Hosts != 3 || summary.Ports != 4 {
+					t.Errorf("shard %d summary = %+v with %d tasks", i, summary, index)
+				}
+				scanned += index
+			}
+
+			if scanned != 12 || len(seen) != 12 {
+				t.Fatalf("shards scanned %d tasks, %d distinct, want 12 of 12", scanned, len(seen))
+			}
+			for task, count := range seen {
+				if count != 1 || task.Port == 3306 {
+					t.Errorf("task %+v selected %d times", task, count)
+				}
+			}
+		})
+	}
+}
+
+func TestCreateScanTasksRoundRobin(t *testing.T) {
+	tasks, _ := createScanTasks(context.Background(), []string{"10.0.0.1", "10.0.0.2"}, []int{1, 2, 3}, nil, Shard{Index: 2, Count: 2})
+
+	var received []types.Task
+	for task := range tasks {
+		received = append(received, task)
+	}
+
+	expected := []types.Task{
+		{Index: 0, Host: "10.0.0.1", Port: 2},
+		{Index: 1, Host: "10.0.0.2", Port: 1},
+		{Index: 2, Host: "10.0.0.2", Port: 3},
+	}
+	if !reflect.DeepEqual(received, expected) {
+		t.Errorf("Tasks = %+v, want %+v", received, expected)
+	}
+}
+
+func TestCreateInputTasksShards(t *testing.T) {
+	targets := []inputTarget{
+		{host: "10.0.0.1", port: 22},
+		{host: "10.0.0.1", port: 80},
+		{host: "10.0.0.2", port: 22},
+	}
+
+	first, summary := createInputTasks(context.Background(), targets, nil, Shard{Index: 1, Count: 2})
+	second, _ := createInputTasks(context.Background(), targets, nil, Shard{Index: 2, Count: 2})
+
+	var firstTasks, secondTasks []types.Task
+	for task := range first {
+		firstTasks = append(firstTasks, task)
+	}
+	for task := range second {
+		secondTasks = append(secondTasks, task)
+	}
+
+	if summary.Scanned != 2 || len(firstTasks) != 2 || len(secondTasks) != 1 {
+		t.Errorf("shards = %d and %d tasks, summary %+v, want 2 and 1", len(firstTasks), len(secondTasks), summary)
+	}
+	if len(secondTasks) == 1 && (secondTasks[0].Host != "10.0.0.1" || secondTasks[0].Port != 80) {
+		t.Errorf("second shard task = %+v, want 10.0.0.1:80", secondTasks[0])
+	}
+}
@@ -0,0 +1,244 @@
+package scanner
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"os"
+	"port-scanner/internal/hostmatch"
+	"strings"
+)
+
+const (
+	maxTargetHosts   = 1 << 16
+	targetSeparator  = ","
+	commentDelimiter = "#"
+)
+
+var (
+	invalidTargetError  = errors.New("invalid target: expected domain, ip address or cidr")
+	tooManyTargetsError = fmt.Errorf("too many targets: expected at most %d hosts", maxTargetHosts)
+	readExcludeError    = errors.New("failed to read exclude file")
+)
+
+type exclusions struct {
+	hosts    map[string]bool
+	names    []string
+	ports    map[int]bool
+	resolve  func(host string) ([]net.IP, error)
+	resolved bool
+}
+
+func splitTargets(address string) []string {
+	targets := make([]string, 0)
+	for _, part := range strings.Split(address, targetSeparator) {
+		if part = strings.TrimSpace(part); part != "" {
+			targets = append(targets, part)
+		}
+	}
+	return targets
+}
+
+func parseTargets(address string) ([]string, error) {
+	hosts := make([]string, 0)
+	seen := make(map[string]bool)
+
+	for _, part := range splitTargets(address) {
+		expanded, err := expandTarget(part)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, host := range expanded {
+			if !seen[host] {
+				seen[host] = true
+				hosts = append(hosts, host)
+			}
+		}
+
+		if len(hosts) > maxTargetHosts {
+			return nil, tooManyTargetsError
+		}
+	}
+
+	if len(hosts) == 0 {
+		return nil, invalidTargetError
+	}
+
+	return hosts, nil
+}
+
+func expandTarget(target string) ([]string, error) {
+	if !strings.Contains(target, "/") {
+		return []string{target}, nil
+	}
+
+	prefix, err := netip.ParsePrefix(target)
+	if err != nil {
+		return nil, invalidTargetError
+	}
+	prefix = prefix.Masked()
+
+	if prefix.Addr().BitLen()-prefix.Bits() > 16 {
+		return nil, tooManyTargetsError
+	}
+
+	hosts := make([]string, 0)
+	for addr := prefix.Addr(); prefix.Contains(addr); addr = addr.Next() {
+		hosts = append(hosts, addr.String())
+	}
+	return hosts, nil
+}
+
+func newExclusions(hosts []string, file string, ports string) (*exclusions, error) {
+	excl := &exclusions{
+		hosts:   make(map[string]bool),
+		ports:   make(map[int]bool),
+		resolve: net.LookupIP,
+	}
+
+	entries := append([]string{}, hosts...)
+	if file != "" {
+		lines, err := readExcludeFile(file)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, lines...)
+	}
+
+	for _, entry := range entries {
+		err := excl.addHost(entry)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, part := range strings.Split(ports, portListSeparator) {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+
+		excluded, err := parsePorts(part)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, port := range excluded {
+			excl.ports[port] = true
+		}
+	}
+
+	return excl, nil
+}
+
+func readExcludeFile(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", readExcludeError, path)
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	entries := make([]string, 0)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), commentDelimiter)
+		for _, entry := range strings.Split(line, targetSeparator) {
+			if entry = strings.TrimSpace(entry); entry != "" {
+				entries = append(entries, entry)
+			}
+		}
+	}
+
+	if scanner.Err() != nil {
+		return nil, fmt.Errorf("%w: %s", readExcludeError, path)
+	}
+
+	return entries, nil
+}
+
+func (e *exclusions) addHost(entry string) error {
+	entry = strings.TrimSpace(entry)
+	if entry == "" {
+		return nil
+	}
+
+	if strings.Contains(entry, "/") {
+		prefix, err := netip.ParsePrefix(entry)
+		if err != nil {
+			return fmt.Errorf("invalid exclude: %q", entry)
+		}
+		e.hosts[prefix.Masked().String()] = true
+		return nil
+	}
+
+	if addr, err := netip.ParseAddr(entry); err == nil {
+		e.hosts[addr.Unmap().String()] = true
+		return nil
+	}
+
+	name := strings.ToLower(entry)
+	if !e.hosts[name] {
+		e.hosts[name] = true
+		e.names = append(e.names, name)
+	}
+	return nil
+}
+
+func (e *exclusions) excludesHost(host string) bool {
+	host = strings.ToLower(host)
+	if e.hosts[host] {
+		return true
+	}
+
+	e.resolveNames()
+	if len(e.hosts) == len(e.names) {
+		return false
+	}
+
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return hostmatch.Lookup(e.hosts, false, addr.Unmap().String())
+	}
+
+	for _, addr := range e.lookup(host) {
+		if hostmatch.Lookup(e.hosts, false, addr.String()) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *exclusions) resolveNames() {
+	if e.resolved {
+		return
+	}
+	e.resolved = true
+
+	for _, name := range e.names {
+		for _, addr := range e.lookup(name) {
+			e.hosts[addr.String()] = true
+		}
+	}
+}
+
+func (e *exclusions) lookup(host string) []netip.Addr {
+	ips, err := e.resolve(host)
+	if err != nil {
+		return nil
+	}
+
+	addrs := make([]netip.Addr, 0, len(ips))
+	for _, ip := range ips {
+		if addr, ok := netip.AddrFromSlice(ip); ok {
+			addrs = append(addrs, addr.Unmap())
+		}
+	}
+	return addrs
+}
+
+func (e *exclusions) excludesPort(port int) bool {
+	return e.ports[port]
+}
@@ -0,0 +1,143 @@
+package scanner
+
+import (
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseTargets(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		expected    []string
+		expectError bool
+	}{
+		{"single ip", "192.168.1.1", []string{"192.168.1.1"}, false},
+		{"domain", "example.com", []string{"example.com"}, false},
+		{"list with spaces", "10.0.0.1, example.com ,10.0.0.2", []string{"10.0.0.1", "example.com", "10.0.0.2"}, false},
+		{"duplicates removed", "10.0.0.1,10.0.0.1", []string{"10.0.0.1"}, false},
+		{"cidr", "10.0.0.0/30", []string{"10.0.0.0", "10.0.0.1", "10.0.0.2", "10.0.0.3"}, false},
+		{"unmasked cidr", "10.0.0.5/31", []string{"10.0.0.4", "10.0.0.5"}, false},
+		{"single host cidr", "10.0.0.5/32", []string{"10.0.0.5"}, false},
+		{"ipv6 cidr", "fd00::/127", []string{"fd00::", "fd00::1"}, false},
+		{"invalid cidr", "10.0.0.0/33", nil, true},
+		{"too large cidr", "10.0.0.0/8", nil, true},
+		{"empty", "", nil, true},
+		{"only separators", " , ", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseTargets(tt.input)
+			if (err != nil) != tt.expectError {
+				t.Fatalf("parseTargets(%q) error = %v, wantErr %v", tt.input, err, tt.expectError)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("parseTargets(%q) = %v, want %v", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestNewExclusions(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "exclude.txt")
+	content := "# production databases\n10.0.0.10\n10.0.1.0/24 # third party\n\ndb.internal, 10.0.2.1\n"
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write exclude file: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		hosts       []string
+		file        string
+		ports       string
+		expectError bool
+	}{
+		{"none", nil, "", "", false},
+		{"hosts and cidrs", []string{"10.0.0.1", "10.0.3.0/24", "Example.com"}, "", "", false},
+		{"file", nil, file, "", false},
+		{"ports", nil, "", "22,3306,5432-5433", false},
+		{"invalid cidr", []string{"10.0.0.0/40"}, "", "", true},
+		{"missing file", nil, filepath.Join(t.TempDir(), "missing.txt"), "", true},
+		{"invalid ports", nil, "", "ssh", true},
+		{"out of range ports", nil, "", "70000", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newExclusions(tt.hosts, tt.file, tt.ports)
+			if (err != nil) != tt.expectError {
+				t.Errorf("newExclusions() error = %v, wantErr %v", err, tt.expectError)
+			}
+		})
+	}
+}
+
+func TestExclusions(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "exclude.txt")
+	if err := os.WriteFile(file, []byte("10.0.1.0/24\ndb.internal\n10.0.3.7/24\n"), 0644); err != nil {
+		t.Fatalf("Failed to write exclude file: %v", err)
+	}
+
+	excl, err := newExclusions([]string{"10.0.0.10", "fd00::/64"}, file, "22,5432-5433")
+	if err != nil {
+		t.Fatalf("newExclusions() unexpected error: %v", err)
+	}
+
+	excl.resolve = func(host string) ([]net.IP, error) {
+		switch host {
+		case "db.internal":
+			return []net.IP{net.ParseIP("10.0.2.5")}, nil
+		case "app.example.com":
+			return []net.IP{net.ParseIP("10.0.1.20")}, nil
+		default:
+			return nil, errors.New("no such host")
+		}
+	}
+
+	hosts := []struct {
+		host     string
+		excluded bool
+	}{
+		{"10.0.0.10", true},
+		{"10.0.0.11", false},
+		{"10.0.1.77", true},
+		{"10.0.3.200", true},
+		{"fd00::1", true},
+		{"fd01::1", false},
+		{"db.internal", true},
+		{"DB.internal", true},
+		{"10.0.2.5", true},
+		{"app.example.com", true},
+		{"unknown.example.com", false},
+	}
+
+	for _, tt := range hosts {
+		t.Run(tt.host, func(t *testing.T) {
+			if got := excl.excludesHost(tt.host); got != tt.excluded {
+				t.Errorf("excludesHost(%q) = %v, want %v", tt.host, got, tt.excluded)
+			}
+		})
+	}
+
+	ports := []struct {
+		port     int
+		excluded bool
+	}{
+		{22, true},
+		{80, false},
+		{5432, true},
+		{5433, true},
+		{5434, false},
+	}
+
+	for _, tt := range ports {
+		if got := excl.excludesPort(tt.port); got != tt.excluded {
+			t.Errorf("excludesPort(%d) = %v, want %v", tt.port, got, tt.excluded)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package server
+
+import (
+	"context"
+	"port-scanner/internal/types"
+	"sync"
+	"time"
+)
+
+type Status string
+
+const (
+	StatusRunning  Status = "running"
+	StatusDone     Status = "done"
+	StatusFailed   Status = "failed"
+	StatusCanceled Status = "canceled"
+)
+
+type scan struct {
+	mu       sync.Mutex
+	id       string
+	cfg      types.Config
+	state    Status
+	created  time.Time
+	finished time.Time
+	total    int
+	scanned  int
+	open     int
+	report   types.Report
+	err      error
+	cancel   context.CancelFunc
+}
+
+type scanStatus struct {
+	ID       string         `json:"id"`
+	Status   Status         `json:"status"`
+	Address  string         `json:"address,omitempty"`
+	Ports    string         `json:"ports,omitempty"`
+	Created  time.Time      `json:"created"`
+	Finished *time.Time     `json:"finished,omitempty"`
+	Progress scanProgress   `json:"progress"`
+	Summary  *types.Summary `json:"summary,omitempty"`
+	Error    string         `json:"error,omitempty"`
+}
+
+type scanProgress struct {
+	Total   int     `json:"total"`
+	Scanned int     `json:"scanned"`
+	Open    int     `json:"open"`
+	Percent float64 `json:"percent"`
+}
+
+func newScan(id string, cfg types.Config, cancel context.CancelFunc) *scan {
+	return &scan{id: id, cfg: cfg, state: StatusRunning, created: time.Now(), cancel: cancel}
+}
+
+func (s *scan) Start(total int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.total = total
+}
+
+func (s *scan) Increment(result types.Result) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.scanned++
+	if result.Status {
+		s.open++
+	}
+}
+
+func (s *scan) Finish() {}
+
+func (s *scan) complete(report types.Report, err error, canceled bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.finished = time.Now()
+	s.cancel()
+	switch {
+	case canceled:
+		s.state = StatusCanceled
+	case err != nil:
+		s.state = StatusFailed
+		s.err = err
+	default:
+		s.state = StatusDone
+		s.report = report
+	}
+}
+
+func (s *scan) cancelRunning() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.state != StatusRunning {
+		return false
+	}
+	s.cancel()
+	return true
+}
+
+func (s *scan) result() (types.Report, types.Config, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.report, s.cfg, s.state == StatusDone
+}
+
+func (s *scan) status() scanStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status := scanStatus{
+		ID:      s.id,
+		Status:  s.state,
+		Address: s.cfg.Address,
+		Ports:   s.cfg.Ports,
+		Created: s.created,
+		Progress: scanProgress{
+			Total:   s.total,
+			Scanned: s.scanned,
+			Open:    s.open,
+		},
+	}
+
+	if s.total > 0 {
+		status.Progress.Percent = float64(s.scanned) * 100 / float64(s.total)
+	}
+	if !s.finished.IsZero() {
+		finished := s.finished
+		status.Finished = &finished
+	}
+	if s.state == StatusDone {
+		summary := s.report.Summary
+		status.Summary = &summary
+	}
+	if s.err != nil {
+		status.Error = s.err.Error()
+	}
+	return status
+}
@@ -0,0 +1,322 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"port-scanner/internal/config"
+	"port-scanner/internal/output"
+	"port-scanner/internal/policy"
+	"port-scanner/internal/scanner"
+	"port-scanner/internal/types"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	contentTypeJSON = "application/json"
+	bearerPrefix    = "Bearer "
+	maxBodySize     = 1 << 20
+	idLength        = 8
+	shutdownTimeout = 5 * time.Second
+)
+
+var (
+	missingTargetError  = errors.New(`scan requires "address"`)
+	scanNotFoundError   = errors.New("scan not found")
+	scanNotDoneError    = errors.New("scan has not finished")
+	unauthorizedError   = errors.New("unauthorized")
+	forbiddenFieldError = errors.New("field cannot be set through the api")
+	restrictedKeys      = []string{
+		"output", "out", "on-change", "state", "listen", "token", "metrics-listen", "webhook", "webhook-secret",
+		"exclude-file", "policy", "expectations", "input-results",
+	}
+)
+
+type ScanFunc func(ctx context.Context, cfg types.Config, reporters ...scanner.Reporter) (types.Report, error)
+
+type Options struct {
+	Config  types.Config
+	Token   string
+	Version string
+	Scan    ScanFunc
+}
+
+type Server struct {
+	opts  Options
+	mux   *http.ServeMux
+	mu    sync.RWMutex
+	scans map[string]*scan
+	order []string
+	wg    sync.WaitGroup
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func New(opts Options) *Server {
+	if opts.Scan == nil {
+		opts.Scan = scanner.Scan
+	}
+
+	s := &Server{opts: opts, mux: http.NewServeMux(), scans: make(map[string]*scan)}
+	s.mux.HandleFunc("POST /scans", s.create)
+	s.mux.HandleFunc("GET /scans", s.list)
+	s.mux.HandleFunc("GET /scans/{id}", s.get)
+	s.mux.HandleFunc("GET /scans/{id}/results", s.results)
+	s.mux.HandleFunc("DELETE /scans/{id}", s.delete)
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !s.authorized(r) {
+		writeError(w, http.StatusUnauthorized, unauthorizedError)
+		return
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) Serve(ctx context.Context, listener net.Listener) error {
+	server := &http.Server{Handler: s, ReadHeaderTimeout: shutdownTimeout}
+
+	go func() {
+		<-ctx.Done()
+		s.cancelAll()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+
+	err := server.Serve(listener)
+	s.wg.Wait()
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+func (s *Server) authorized(r *http.Request) bool {
+	if s.opts.Token == "" {
+		return true
+	}
+
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), bearerPrefix)
+	return ok && subtle.ConstantTimeCompare([]byte(token), []byte(s.opts.Token)) == 1
+}
+
+func (s *Server) create(w http.ResponseWriter, r *http.Request) {
+	cfg, err := s.decodeConfig(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	id, err := newID()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	sc := newScan(id, cfg, cancel)
+
+	s.mu.Lock()
+	s.scans[id] = sc
+	s.order = append(s.order, id)
+	s.mu.Unlock()
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		report, err := s.opts.Scan(ctx, cfg, sc)
+		sc.complete(report, err, ctx.Err() != nil)
+	}()
+
+	w.Header().Set("Location", "/scans/"+id)
+	writeJSON(w, http.StatusAccepted, sc.status())
+}
+
+func (s *Server) decodeConfig(r *http.Request) (types.Config, error) {
+	values := make(map[string]any)
+	err := json.NewDecoder(http.MaxBytesReader(nil, r.Body, maxBodySize)).Decode(&values)
+	if err != nil {
+		return types.Config{}, fmt.Errorf("invalid request body: %w", err)
+	}
+
+	for key := range values {
+		if slices.Contains(restrictedKeys, key) {
+			return types.Config{}, fmt.Errorf("%w: %q", forbiddenFieldError, key)
+		}
+	}
+
+	cfg := s.opts.Config
+	cfg.Exclude, cfg.ExcludePorts = nil, ""
+	err = config.Apply(&cfg, values)
+	if err != nil {
+		return types.Config{}, err
+	}
+	cfg.Output, cfg.Outputs, cfg.Quiet = "", nil, true
+	cfg.Exclude = append(slices.Clone(s.opts.Config.Exclude), cfg.Exclude...)
+	cfg.ExcludePorts = joinPorts(s.opts.Config.ExcludePorts, cfg.ExcludePorts)
+
+	if cfg.Address == "" && cfg.InputResults == "" {
+		return types.Config{}, missingTargetError
+	}
+
+	_, err = output.ParseFilter(cfg.Filter)
+	if err != nil {
+		return types.Config{}, err
+	}
+
+	return cfg, nil
+}
+
+func joinPorts(server, request string) string {
+	if server == "" || request == "" {
+		return server + request
+	}
+	return server + "," + request
+}
+
+func (s *Server) list(w http.ResponseWriter, _ *http.Request) {
+	s.mu.RLock()
+	statuses := make([]scanStatus, 0, len(s.order))
+	for _, id := range s.order {
+		statuses = append(statuses, s.scans[id].status())
+	}
+	s.mu.RUnlock()
+
+	writeJSON(w, http.StatusOK, statuses)
+}
+
+func (s *Server) get(w http.ResponseWriter, r *http.Request) {
+	sc, ok := s.lookup(r.PathValue("id"))
+	if !ok {
+		writeError(w, http.StatusNotFound, scanNotFoundError)
+		return
+	}
+	writeJSON(w, http.StatusOK, sc.status())
+}
+
+func (s *Server) results(w http.ResponseWriter, r *http.Request) {
+	sc, ok := s.lookup(r.PathValue("id"))
+	if !ok {
+		writeError(w, http.StatusNotFound, scanNotFoundError)
+		return
+	}
+
+	format := output.FormatJson
+	if value := r.URL.Query().Get("format"); value != "" {
+		parsed, err := output.ParseFormat(value)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		format = parsed
+	}
+
+	report, cfg, done := sc.result()
+	if !done {
+		writeError(w, http.StatusConflict, scanNotDoneError)
+		return
+	}
+
+	report.Metadata.Version = s.opts.Version
+	content, err := render(report, format, cfg)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", format.ContentType())
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(content))
+}
+
+func (s *Server) delete(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	sc, ok := s.lookup(id)
+	if !ok {
+		writeError(w, http.StatusNotFound, scanNotFoundError)
+		return
+	}
+
+	if sc.cancelRunning() {
+		writeJSON(w, http.StatusAccepted, sc.status())
+		return
+	}
+
+	s.mu.Lock()
+	delete(s.scans, id)
+	s.order = slices.DeleteFunc(s.order, func(other string) bool { return other == id })
+	s.mu.Unlock()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) lookup(id string) (*scan, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	sc, ok := s.scans[id]
+	return sc, ok
+}
+
+func (s *Server) cancelAll() {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, sc := range s.scans {
+		sc.cancelRunning()
+	}
+}
+
+func render(report types.Report, format output.Format, cfg types.Config) (string, error) {
+	filter, err := output.NewFilter(cfg)
+	if err != nil {
+		return "", err
+	}
+
+	pol, err := policy.Load(cfg.Policy)
+	if err != nil {
+		return "", err
+	}
+	report.Violations = pol.Evaluate(report.Results)
+
+	results := make([]types.Result, 0, len(report.Results))
+	for _, r := range report.Results {
+		if filter(r) {
+			results = append(results, r)
+		}
+	}
+	report.Results = results
+
+	return output.Render(report, format, cfg)
+}
+
+func newID() (string, error) {
+	buf := make([]byte, idLength)
+	_, err := rand.Read(buf)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", contentTypeJSON)
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, errorResponse{Error: err.Error()})
+}
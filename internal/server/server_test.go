@@ -0,0 +1,278 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"port-scanner/internal/config"
+	"port-scanner/internal/scanner"
+	"port-scanner/internal/types"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testResults = []types.Result{
+	{Host: "10.0.0.1", Port: 22, Status: true, Service: "ssh"},
+	{Host: "10.0.0.1", Port: 23, Status: false},
+}
+
+func fakeScan(release chan struct{}) ScanFunc {
+	return func(ctx context.Context, cfg types.Config, reporters ...scanner.Reporter) (types.Report, error) {
+		for _, r := range reporters {
+			r.Start(len(testResults))
+			r.Increment(testResults[0])
+		}
+
+		select {
+		case <-ctx.Done():
+			return types.Report{}, ctx.Err()
+		case <-release:
+		}
+
+		for _, r := range reporters {
+			r.Increment(testResults[1])
+			r.Finish()
+		}
+		return types.Report{Results: testResults, Summary: types.Summary{Hosts: 1, Ports: 2, Scanned: 2, Open: 1}}, nil
+	}
+}
+
+func request(t *testing.T, handler http.Handler, method, path, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func decodeStatus(t *testing.T, rec *httptest.ResponseRecorder) scanStatus {
+	t.Helper()
+	var status scanStatus
+	if err := json.Unmarshal(rec.Body.Bytes(), &status); err != nil {
+		t.Fatalf("invalid status %q: %v", rec.Body.String(), err)
+	}
+	return status
+}
+
+func waitFor(t *testing.T, handler http.Handler, id string, ready func(scanStatus) bool) scanStatus {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		status := decodeStatus(t, request(t, handler, http.MethodGet, "/scans/"+id, ""))
+		if ready(status) {
+			return status
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("status = %+v did not become ready", status)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func waitForStatus(t *testing.T, handler http.Handler, id string, want Status) scanStatus {
+	t.Helper()
+	return waitFor(t, handler, id, func(status scanStatus) bool { return status.Status == want })
+}
+
+func TestScanLifecycle(t *testing.T) {
+	release := make(chan struct{})
+	srv := New(Options{Config: config.Default(), Scan: fakeScan(release)})
+
+	rec := request(t, srv, http.MethodPost, "/scans", `{"address":"10.0.0.1","ports":"22,23","timeout":250,"exclude":["10.0.0.2"]}`)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("POST /scans = %d %s, want 202", rec.Code, rec.Body)
+	}
+	created := decodeStatus(t, rec)
+	if rec.Header().Get("Location") != "/scans/"+created.ID {
+		t.Errorf("Location = %q, want /scans/%s", rec.Header().Get("Location"), created.ID)
+	}
+
+	sc, _ := srv.lookup(created.ID)
+	if sc.cfg.Timeout != 250 || sc.cfg.Mode != "default" || !sc.cfg.Quiet {
+		t.Errorf("config = %+v, want request fields over defaults", sc.cfg)
+	}
+
+	running := waitFor(t, srv, created.ID, func(status scanStatus) bool { return status.Progress.Total > 0 })
+	if running.Status != StatusRunning || running.Progress.Total != 2 || running.Progress.Scanned != 1 || running.Progress.Percent != 50 {
+		t.Errorf("status = %+v, want running at 50%%", running)
+	}
+
+	rec = request(t, srv, http.MethodGet, "/scans/"+created.ID+"/results", "")
+	if rec.Code != http.StatusConflict {
+		t.Errorf("GET results while running = %d, want 409", rec.Code)
+	}
+
+	close(release)
+	done := waitForStatus(t, srv, created.ID, StatusDone)
+	if done.Progress.Scanned != 2 || done.Progress.Percent != 100 || done.Summary == nil || done.Summary.Open != 1 {
+		t.Errorf("status = %+v, want finished progress and summary", done)
+	}
+
+	tests := []struct {
+		query       string
+		code        int
+		contentType string
+		contains    string
+	}{
+		{"", http.StatusOK, "application/json", `"schema_version": 1`},
+		{"?format=csv", http.StatusOK, "text/csv; charset=utf-8", "10.0.0.1,22,true"},
+		{"?format=xml", http.StatusOK, "application/xml", `<port protocol="tcp" portid="22">`},
+		{"?format=yaml", http.StatusBadRequest, "application/json", "invalid format"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			rec := request(t, srv, http.MethodGet, "/scans/"+created.ID+"/results"+tt.query, "")
+			if rec.Code != tt.code {
+				t.Errorf("code = %d, want %d", rec.Code, tt.code)
+			}
+			if got := rec.Header().Get("Content-Type"); got != tt.contentType {
+				t.Errorf("Content-Type = %q, want %q", got, tt.contentType)
+			}
+			if !strings.Contains(rec.Body.String(), tt.contains) {
+				t.Errorf("body = %q, want it to contain %q", rec.Body, tt.contains)
+			}
+		})
+	}
+
+	rec = request(t, srv, http.MethodDelete, "/scans/"+created.ID, "")
+	if rec.Code != http.StatusNoContent {
+		t.Errorf("DELETE finished scan = %d, want 204", rec.Code)
+	}
+	rec = request(t, srv, http.MethodGet, "/scans/"+created.ID, "")
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("GET deleted scan = %d, want 404", rec.Code)
+	}
+}
+
+func TestServerExclusions(t *testing.T) {
+	cfg := config.Default()
+	cfg.Exclude, cfg.ExcludePorts = []string{"10.0.0.9"}, "22"
+	srv := New(Options{Config: cfg, Scan: fakeScan(make(chan struct{}))})
+
+	tests := []struct {
+		name    string
+		body    string
+		exclude []string
+		ports   string
+	}{
+		{"server only", `{"address":"10.0.0.0/24"}`, []string{"10.0.0.9"}, "22"},
+		{"empty request", `{"address":"10.0.0.0/24","exclude":[],"exclude-ports":""}`, []string{"10.0.0.9"}, "22"},
+		{"merged", `{"address":"10.0.0.0/24","exclude":["10.0.0.2"],"exclude-ports":"23"}`, []string{"10.0.0.9", "10.0.0.2"}, "22,23"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := request(t, srv, http.MethodPost, "/scans", tt.body)
+			if rec.Code != http.StatusAccepted {
+				t.Fatalf("POST /scans = %d %s, want 202", rec.Code, rec.Body)
+			}
+
+			sc, _ := srv.lookup(decodeStatus(t, rec).ID)
+			if !reflect.DeepEqual(sc.cfg.Exclude, tt.exclude) || sc.cfg.ExcludePorts != tt.ports {
+				t.Errorf("exclusions = %v %q, want %v %q", sc.cfg.Exclude, sc.cfg.ExcludePorts, tt.exclude, tt.ports)
+			}
+		})
+	}
+
+	if !reflect.DeepEqual(cfg.Exclude, []string{"10.0.0.9"}) {
+		t.Errorf("server exclusions = %v, want them unchanged", cfg.Exclude)
+	}
+	srv.cancelAll()
+}
+
+func TestResultsFilter(t *testing.T) {
+	release := make(chan struct{})
+	close(release)
+	srv := New(Options{Config: config.Default(), Scan: fakeScan(release)})
+
+	created := decodeStatus(t, request(t, srv, http.MethodPost, "/scans", `{"address":"10.0.0.1","open-only":true}`))
+	waitForStatus(t, srv, created.ID, StatusDone)
+
+	rec := request(t, srv, http.MethodGet, "/scans/"+created.ID+"/results?format=csv", "")
+	if strings.Contains(rec.Body.String(), ",23,") {
+		t.Errorf("body = %q, want closed ports filtered", rec.Body)
+	}
+}
+
+func TestCancelScan(t *testing.T) {
+	srv := New(Options{Config: config.Default(), Scan: fakeScan(make(chan struct{}))})
+
+	created := decodeStatus(t, request(t, srv, http.MethodPost, "/scans", `{"address":"10.0.0.1"}`))
+
+	rec := request(t, srv, http.MethodDelete, "/scans/"+created.ID, "")
+	if rec.Code != http.StatusAccepted {
+		t.Errorf("DELETE running scan = %d, want 202", rec.Code)
+	}
+
+	waitForStatus(t, srv, created.ID, StatusCanceled)
+	rec = request(t, srv, http.MethodGet, "/scans/"+created.ID+"/results", "")
+	if rec.Code != http.StatusConflict {
+		t.Errorf("GET results of canceled scan = %d, want 409", rec.Code)
+	}
+}
+
+func TestCreateErrors(t *testing.T) {
+	srv := New(Options{Config: config.Default(), Scan: fakeScan(make(chan struct{}))})
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"invalid json", `{"address":`},
+		{"missing address", `{"ports":"80"}`},
+		{"unknown field", `{"address":"10.0.0.1","speed":"fast"}`},
+		{"invalid type", `{"address":"10.0.0.1","timeout":"soon"}`},
+		{"restricted field", `{"address":"10.0.0.1","output":"/etc/cron.d/x"}`},
+		{"invalid filter", `{"address":"10.0.0.1","filter":"port >"}`},
+		{"restricted policy", `{"address":"10.0.0.1","policy":"/etc/passwd"}`},
+		{"restricted expectations", `{"address":"10.0.0.1","expectations":"/etc/passwd"}`},
+		{"restricted input", `{"input-results":"/etc/passwd"}`},
+		{"restricted exclude file", `{"address":"10.0.0.1","exclude-file":"/dev/null"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := request(t, srv, http.MethodPost, "/scans", tt.body)
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("POST /scans = %d %s, want 400", rec.Code, rec.Body)
+			}
+		})
+	}
+
+	rec := request(t, srv, http.MethodGet, "/scans", "")
+	if strings.TrimSpace(rec.Body.String()) != "[]" {
+		t.Errorf("GET /scans = %q, want no scans", rec.Body)
+	}
+}
+
+func TestToken(t *testing.T) {
+	srv := New(Options{Config: config.Default(), Token: "secret", Scan: fakeScan(make(chan struct{}))})
+
+	tests := []struct {
+		name   string
+		header string
+		code   int
+	}{
+		{"missing token", "", http.StatusUnauthorized},
+		{"wrong token", "Bearer wrong", http.StatusUnauthorized},
+		{"valid token", "Bearer secret", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/scans", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			srv.ServeHTTP(rec, req)
+			if rec.Code != tt.code {
+				t.Errorf("GET /scans = %d, want %d", rec.Code, tt.code)
+			}
+		})
+	}
+}
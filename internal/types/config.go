@@ -1,10 +1,44 @@
 package types
 
 type Config struct {
-	Address string
-	Ports   string
-	Mode    string
-	Output  string
-	Format  string
-	Timeout int
+	Address        string   `yaml:"address"`
+	Ports          string   `yaml:"ports"`
+	Mode           string   `yaml:"mode"`
+	Output         string   `yaml:"output"`
+	Format         string   `yaml:"format"`
+	JsonLegacy     bool     `yaml:"json-legacy"`
+	Outputs        []string `yaml:"out"`
+	OpenOnly       bool     `yaml:"open-only"`
+	Filter         string   `yaml:"filter"`
+	NdjsonMeta     bool     `yaml:"ndjson-meta"`
+	Expectations   string   `yaml:"expectations"`
+	Policy         string   `yaml:"policy"`
+	History        string   `yaml:"history"`
+	InputResults   string   `yaml:"input-results"`
+	InputOpenOnly  bool     `yaml:"input-open-only"`
+	Timeout        int      `yaml:"timeout"`
+	ScanDelay      int      `yaml:"scan-delay"`
+	MaxJitter      int      `yaml:"max-jitter"`
+	Banners        bool     `yaml:"banners"`
+	Exclude        []string `yaml:"exclude"`
+	ExcludeFile    string   `yaml:"exclude-file"`
+	ExcludePorts   string   `yaml:"exclude-ports"`
+	Shard          string   `yaml:"shard"`
+	ShardSeed      int      `yaml:"shard-seed"`
+	Progress       string   `yaml:"progress"`
+	Quiet          bool     `yaml:"quiet"`
+	Interval       int      `yaml:"interval"`
+	State          string   `yaml:"state"`
+	OnChange       string   `yaml:"on-change"`
+	ExitOnChange   bool     `yaml:"exit-on-change"`
+	MetricsListen  string   `yaml:"metrics-listen"`
+	Webhooks       []string `yaml:"webhook"`
+	WebhookSecret  string   `yaml:"webhook-secret"`
+	WebhookTimeout int      `yaml:"webhook-timeout"`
+	WebhookRetries int      `yaml:"webhook-retries"`
+	Listen         string   `yaml:"listen"`
+	Token          string   `yaml:"token"`
+	Coordinator    string   `yaml:"coordinator"`
+	BatchSize      int      `yaml:"batch-size"`
+	LeaseTimeout   int      `yaml:"lease-timeout"`
 }
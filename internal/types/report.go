@@ -0,0 +1,41 @@
+package types
+
+import "time"
+
+type Summary struct {
+	Hosts         int `json:"hosts"`
+	Ports         int `json:"ports"`
+	Scanned       int `json:"scanned"`
+	Open          int `json:"open"`
+	ExcludedHosts int `json:"excluded_hosts"`
+	ExcludedPorts int `json:"excluded_ports"`
+	Skipped       int `json:"skipped,omitempty"`
+}
+
+type Metadata struct {
+	Version     string        `json:"version"`
+	Args        []string      `json:"args"`
+	Targets     []string      `json:"targets"`
+	Ports       string        `json:"ports"`
+	Input       string        `json:"input,omitempty"`
+	Shard       string        `json:"shard,omitempty"`
+	Mode        string        `json:"mode"`
+	Timeout     time.Duration `json:"timeout"`
+	Concurrency int           `json:"concurrency"`
+	Start       time.Time     `json:"start"`
+	End         time.Time     `json:"end"`
+}
+
+type Violation struct {
+	Host    string `json:"host"`
+	Port    int    `json:"port"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+type Report struct {
+	Results    []Result
+	Summary    Summary
+	Metadata   Metadata
+	Violations []Violation
+}
@@ -1,6 +1,12 @@
 package types
 
 type Result struct {
-	Port   int  `json:"port"`
-	Status bool `json:"status"`
+	Host    string  `json:"host,omitempty"`
+	Address string  `json:"address,omitempty"`
+	Port    int     `json:"port"`
+	Status  bool    `json:"status"`
+	Service string  `json:"service,omitempty"`
+	Banner  string  `json:"banner,omitempty"`
+	Latency float64 `json:"latency_ms,omitempty"`
+	Skipped bool    `json:"skipped,omitempty"`
 }
@@ -1,6 +1,7 @@
 package types
 
 type Task struct {
-	Index int
-	Port  int
+	Index int    `json:"index"`
+	Host  string `json:"host"`
+	Port  int    `json:"port"`
 }
@@ -0,0 +1,11 @@
+package utils
+
+import (
+	"os"
+
+	"golang.org/x/term"
+)
+
+func IsTerminal(f *os.File) bool {
+	return term.IsTerminal(int(f.Fd()))
+}
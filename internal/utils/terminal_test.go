@@ -0,0 +1,21 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIsTerminal(t *testing.T) {
+	f, err := os.Create(filepath.Join(t.TempDir(), "output"))
+	if err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	if IsTerminal(f) {
+		t.Errorf("IsTerminal() = true for regular file, want false")
+	}
+}
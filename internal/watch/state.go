@@ -0,0 +1,71 @@
+package watch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"port-scanner/internal/types"
+)
+
+const (
+	directoryPermission = 0755
+	filePermission      = 0644
+)
+
+var (
+	readStateError  = errors.New("failed to read state file")
+	writeStateError = errors.New("failed to write state file")
+)
+
+func LoadState(path string) ([]types.Result, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("%w: %s", readStateError, path)
+	}
+
+	var results []types.Result
+	err = json.Unmarshal(data, &results)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", readStateError, path)
+	}
+
+	return results, nil
+}
+
+func SaveState(path string, results []types.Result) error {
+	if path == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(results)
+	if err != nil {
+		return writeStateError
+	}
+
+	err = os.MkdirAll(filepath.Dir(path), directoryPermission)
+	if err != nil {
+		return fmt.Errorf("%w: %s", writeStateError, path)
+	}
+
+	tmp := path + ".tmp"
+	err = os.WriteFile(tmp, data, filePermission)
+	if err != nil {
+		return fmt.Errorf("%w: %s", writeStateError, path)
+	}
+
+	err = os.Rename(tmp, path)
+	if err != nil {
+		return fmt.Errorf("%w: %s", writeStateError, path)
+	}
+
+	return nil
+}
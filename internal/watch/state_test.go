@@ -0,0 +1,61 @@
+package watch
+
+import (
+	"os"
+	"path/filepath"
+	"port-scanner/internal/types"
+	"reflect"
+	"testing"
+)
+
+func TestStateRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "state.json")
+	results := []types.Result{
+		{Port: 22, Status: true, Banner: "SSH-2.0-OpenSSH_9.6"},
+		{Port: 80, Status: false},
+	}
+
+	err := SaveState(path, results)
+	if err != nil {
+		t.Fatalf("SaveState() unexpected error: %v", err)
+	}
+
+	got, err := LoadState(path)
+	if err != nil {
+		t.Fatalf("LoadState() unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(got, results) {
+		t.Errorf("LoadState() = %+v, want %+v", got, results)
+	}
+}
+
+func TestLoadState(t *testing.T) {
+	dir := t.TempDir()
+	invalid := filepath.Join(dir, "invalid.json")
+	if err := os.WriteFile(invalid, []byte("{"), 0644); err != nil {
+		t.Fatalf("Failed to write state: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		path      string
+		expectErr bool
+	}{
+		{"empty path", "", false},
+		{"missing file", filepath.Join(dir, "missing.json"), false},
+		{"invalid file", invalid, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := LoadState(tt.path)
+			if (err != nil) != tt.expectErr {
+				t.Errorf("LoadState() error = %v, wantErr %v", err, tt.expectErr)
+			}
+			if got != nil {
+				t.Errorf("LoadState() = %+v, want nil", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package watch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"port-scanner/internal/diff"
+	"port-scanner/internal/types"
+	"strconv"
+	"time"
+)
+
+const (
+	hookShell      = "/bin/sh"
+	hookShellFlag  = "-c"
+	envChangeCount = "PORT_SCANNER_CHANGES"
+)
+
+var (
+	ChangesDetectedError = errors.New("changes detected")
+	hookFailedError      = errors.New("on-change hook failed")
+)
+
+type Event struct {
+	Time time.Time `json:"time"`
+	diff.Change
+}
+
+type ScanFunc func(ctx context.Context, cfg types.Config) (types.Report, error)
+
+type ChangeFunc func(report types.Report, events []Event) error
+
+type Options struct {
+	Config       types.Config
+	Interval     time.Duration
+	State        string
+	Hook         string
+	ExitOnChange bool
+	Scan         ScanFunc
+	OnChange     ChangeFunc
+	Events       io.Writer
+	Errors       io.Writer
+}
+
+func Run(ctx context.Context, opts Options) error {
+	previous, err := LoadState(opts.State)
+	if err != nil {
+		return err
+	}
+
+	for {
+		report, err := opts.Scan(ctx, opts.Config)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+
+		current := keepSkipped(previous, report.Results)
+
+		var events []Event
+		if previous != nil {
+			events = newEvents(diff.Changes(previous, current))
+		}
+
+		err = SaveState(opts.State, current)
+		if err != nil {
+			return err
+		}
+		previous = current
+
+		if len(events) > 0 {
+			err = handleEvents(ctx, opts, report, events)
+			if err != nil {
+				return err
+			}
+
+			if opts.ExitOnChange {
+				return ChangesDetectedError
+			}
+		}
+
+		if !sleep(ctx, opts.Interval) {
+			return nil
+		}
+	}
+}
+
+type endpoint struct {
+	host string
+	port int
+}
+
+func keepSkipped(previous, current []types.Result) []types.Result {
+	known := make(map[endpoint]types.Result, len(previous))
+	for _, r := range previous {
+		known[endpoint{host: r.Host, port: r.Port}] = r
+	}
+
+	results := make([]types.Result, 0, len(current))
+	for _, r := range current {
+		if last, ok := known[endpoint{host: r.Host, port: r.Port}]; ok && r.Skipped {
+			r = last
+		}
+		results = append(results, r)
+	}
+	return results
+}
+
+func newEvents(changes []diff.Change) []Event {
+	now := time.Now().UTC()
+	events := make([]Event, 0, len(changes))
+	for _, c := range changes {
+		events = append(events, Event{Time: now, Change: c})
+	}
+	return events
+}
+
+func handleEvents(ctx context.Context, opts Options, report types.Report, events []Event) error {
+	data, err := encodeEvents(events)
+	if err != nil {
+		return err
+	}
+
+	if opts.Events != nil {
+		_, err = opts.Events.Write(data)
+		if err != nil {
+			return err
+		}
+	}
+
+	if opts.OnChange != nil {
+		err = opts.OnChange(report, events)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = runHook(ctx, opts.Hook, data, len(events))
+	if err != nil && ctx.Err() == nil && opts.Errors != nil {
+		_, _ = fmt.Fprintf(opts.Errors, "%v\n", err)
+	}
+	return nil
+}
+
+func encodeEvents(events []Event) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for _, e := range events {
+		err := encoder.Encode(e)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+func runHook(ctx context.Context, hook string, events []byte, count int) error {
+	if hook == "" {
+		return nil
+	}
+
+	cmd := exec.CommandContext(ctx, hookShell, hookShellFlag, hook)
+	cmd.Stdin = bytes.NewReader(events)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(), envChangeCount+"="+strconv.Itoa(count))
+
+	err := cmd.Run()
+	if err != nil {
+		return fmt.Errorf("%w: %v", hookFailedError, err)
+	}
+	return nil
+}
+
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
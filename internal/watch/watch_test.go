@@ -0,0 +1,289 @@
+package watch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"port-scanner/internal/diff"
+	"port-scanner/internal/types"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func sequenceScan(scans ...[]types.Result) (ScanFunc, *int) {
+	calls := 0
+	return func(ctx context.Context, _ types.Config) (types.Report, error) {
+		if calls >= len(scans) {
+			return types.Report{}, ctx.Err()
+		}
+		results := scans[calls]
+		calls++
+		return types.Report{Results: results, Summary: types.Summary{Scanned: len(results)}}, nil
+	}, &calls
+}
+
+func TestRun(t *testing.T) {
+	scans := [][]types.Result{
+		{{Host: "127.0.0.1", Port: 22, Status: true}, {Host: "127.0.0.1", Port: 80, Status: false}},
+		{{Host: "127.0.0.1", Port: 22, Status: true}, {Host: "127.0.0.1", Port: 80, Status: false}},
+		{{Host: "127.0.0.1", Port: 22, Status: false}, {Host: "127.0.0.1", Port: 80, Status: true}},
+	}
+
+	tests := []struct {
+		name         string
+		exitOnChange bool
+		expectErr    error
+		expected     []diff.Change
+		scans        int
+	}{
+		{
+			name:     "emits only changes",
+			expected: []diff.Change{{Kind: diff.KindClosed, Host: "127.0.0.1", Port: 22}, {Kind: diff.KindOpened, Host: "127.0.0.1", Port: 80}},
+			scans:    len(scans),
+		},
+		{
+			name:         "exit on change",
+			exitOnChange: true,
+			expectErr:    ChangesDetectedError,
+			expected:     []diff.Change{{Kind: diff.KindClosed, Host: "127.0.0.1", Port: 22}, {Kind: diff.KindOpened, Host: "127.0.0.1", Port: 80}},
+			scans:        len(scans),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			scan, calls := sequenceScan(scans...)
+			var events bytes.Buffer
+			var changed []Event
+			var reports []types.Report
+
+			err := Run(ctx, Options{
+				Config:       types.Config{Address: "127.0.0.1"},
+				Interval:     time.Millisecond,
+				ExitOnChange: tt.exitOnChange,
+				Scan: func(ctx context.Context, cfg types.Config) (types.Report, error) {
+					if *calls == len(scans) {
+						cancel()
+					}
+					return scan(ctx, cfg)
+				},
+				OnChange: func(report types.Report, e []Event) error {
+					changed = append(changed, e...)
+					reports = append(reports, report)
+					return nil
+				},
+				Events: &events,
+			})
+			if !errors.Is(err, tt.expectErr) {
+				t.Fatalf("Run() error = %v, want %v", err, tt.expectErr)
+			}
+
+			if *calls != tt.scans {
+				t.Errorf("Run() scans = %d, want %d", *calls, tt.scans)
+			}
+			if len(reports) != 1 || reports[0].Summary.Scanned != 2 || len(reports[0].Results) != 2 {
+				t.Errorf("Run() passed reports %+v, want the full scan report", reports)
+			}
+
+			lines := strings.Split(strings.TrimSpace(events.String()), "\n")
+			if len(lines) != len(tt.expected) || len(changed) != len(tt.expected) {
+				t.Fatalf("Run() emitted %d events, want %d: %q", len(lines), len(tt.expected), events.String())
+			}
+
+			for i, line := range lines {
+				var event Event
+				if err := json.Unmarshal([]byte(line), &event); err != nil {
+					t.Fatalf("Run() produced invalid NDJSON: %v", err)
+				}
+				if event.Change != tt.expected[i] {
+					t.Errorf("Event[%d] = %+v, want %+v", i, event, tt.expected[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRunKeepsStateOfSkippedPorts(t *testing.T) {
+	state := filepath.Join(t.TempDir(), "state.json")
+	scans := [][]types.Result{
+		{{Host: "127.0.0.1", Port: 22, Status: true}},
+		{{Host: "127.0.0.1", Port: 22, Skipped: true}},
+		{{Host: "127.0.0.1", Port: 22, Status: false}},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	scan, calls := sequenceScan(scans...)
+	var events bytes.Buffer
+	var saved [][]types.Result
+
+	err := Run(ctx, Options{
+		Config:   types.Config{Address: "127.0.0.1"},
+		Interval: time.Millisecond,
+		State:    state,
+		Scan: func(ctx context.Context, cfg types.Config) (types.Report, error) {
+			if *calls > 0 {
+				results, err := LoadState(state)
+				if err != nil {
+					t.Fatalf("LoadState() unexpected error: %v", err)
+				}
+				saved = append(saved, results)
+			}
+			if *calls == len(scans) {
+				cancel()
+			}
+			return scan(ctx, cfg)
+		},
+		Events: &events,
+	})
+	if err != nil {
+		t.Fatalf("Run() unexpected error: %v", err)
+	}
+
+	if len(saved) < 2 || len(saved[1]) != 1 || !saved[1][0].Status || saved[1][0].Skipped {
+		t.Errorf("SaveState() after skipped scan = %+v, want the previous open result", saved)
+	}
+	if !strings.Contains(events.String(), `"kind":"closed"`) {
+		t.Errorf("Run() events = %q, want the port closed after it was skipped", events.String())
+	}
+}
+
+func TestKeepSkipped(t *testing.T) {
+	previous := []types.Result{
+		{Host: "10.0.0.1", Port: 22, Status: true},
+		{Host: "10.0.0.1", Port: 80, Status: false},
+	}
+	current := []types.Result{
+		{Host: "10.0.0.1", Port: 22, Skipped: true},
+		{Host: "10.0.0.1", Port: 80, Status: true},
+		{Host: "10.0.0.1", Port: 443, Skipped: true},
+	}
+	expected := []types.Result{
+		{Host: "10.0.0.1", Port: 22, Status: true},
+		{Host: "10.0.0.1", Port: 80, Status: true},
+		{Host: "10.0.0.1", Port: 443, Skipped: true},
+	}
+
+	if got := keepSkipped(previous, current); !reflect.DeepEqual(got, expected) {
+		t.Errorf("keepSkipped() = %+v, want %+v", got, expected)
+	}
+}
+
+func TestRunWithState(t *testing.T) {
+	state := filepath.Join(t.TempDir(), "state.json")
+	err := SaveState(state, []types.Result{{Port: 443, Status: true}})
+	if err != nil {
+		t.Fatalf("SaveState() unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var events bytes.Buffer
+
+	err = Run(ctx, Options{
+		Config:   types.Config{Address: "127.0.0.1"},
+		Interval: time.Millisecond,
+		State:    state,
+		Scan: func(context.Context, types.Config) (types.Report, error) {
+			cancel()
+			return types.Report{Results: []types.Result{{Port: 443, Status: false}}}, nil
+		},
+		Events: &events,
+	})
+	if err != nil {
+		t.Fatalf("Run() unexpected error: %v", err)
+	}
+
+	if !strings.Contains(events.String(), `"kind":"closed"`) {
+		t.Errorf("Run() events = %q, want closed event from persisted state", events.String())
+	}
+
+	saved, err := LoadState(state)
+	if err != nil {
+		t.Fatalf("LoadState() unexpected error: %v", err)
+	}
+	if len(saved) != 1 || saved[0].Status {
+		t.Errorf("LoadState() = %+v, want latest scan", saved)
+	}
+}
+
+func TestRunFailingHook(t *testing.T) {
+	scans := [][]types.Result{
+		{{Host: "127.0.0.1", Port: 22, Status: true}},
+		{{Host: "127.0.0.1", Port: 22, Status: false}},
+		{{Host: "127.0.0.1", Port: 22, Status: true}},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	scan, calls := sequenceScan(scans...)
+	var events, errs bytes.Buffer
+
+	err := Run(ctx, Options{
+		Config:   types.Config{Address: "127.0.0.1"},
+		Interval: time.Millisecond,
+		Hook:     "exit 1",
+		Scan: func(ctx context.Context, cfg types.Config) (types.Report, error) {
+			if *calls == len(scans) {
+				cancel()
+			}
+			return scan(ctx, cfg)
+		},
+		Events: &events,
+		Errors: &errs,
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v, want watching to continue after a failed hook", err)
+	}
+
+	if *calls != len(scans) {
+		t.Errorf("Run() scans = %d, want %d", *calls, len(scans))
+	}
+	if lines := strings.Split(strings.TrimSpace(events.String()), "\n"); len(lines) != 2 {
+		t.Errorf("Run() emitted %d events, want 2: %q", len(lines), events.String())
+	}
+	if strings.Count(errs.String(), hookFailedError.Error()) != 2 {
+		t.Errorf("Run() errors = %q, want both hook failures reported", errs.String())
+	}
+}
+
+func TestRunHook(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "hook.out")
+	events := []byte(`{"kind":"opened","port":80}` + "\n")
+
+	tests := []struct {
+		name      string
+		hook      string
+		expectErr bool
+	}{
+		{"no hook", "", false},
+		{"hook receives events", "cat > " + out + " && test \"$PORT_SCANNER_CHANGES\" = 1", false},
+		{"failing hook", "exit 1", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := runHook(context.Background(), tt.hook, events, 1)
+			if (err != nil) != tt.expectErr {
+				t.Errorf("runHook() error = %v, wantErr %v", err, tt.expectErr)
+			}
+		})
+	}
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("Failed to read hook output: %v", err)
+	}
+	if !bytes.Equal(data, events) {
+		t.Errorf("hook stdin = %q, want %q", data, events)
+	}
+}